@@ -16,7 +16,11 @@ limitations under the License.
 
 package parse
 
-import "strings"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // ParseSELinuxLabel parses a SELinux label string into its components.
 // Format: "user:role:type:level" -> [user, role, type, level]
@@ -30,3 +34,177 @@ func ParseSELinuxLabel(label string) [4]string {
 	copy(parts[:], split)
 	return parts
 }
+
+// FormatSELinuxLabel joins the components returned by ParseSELinuxLabel back into a
+// single "user:role:type:level" string. Trailing empty components are omitted, mirroring
+// how a partial label (e.g. just a type) is usually written.
+func FormatSELinuxLabel(parts [4]string) string {
+	last := -1
+	for i, p := range parts {
+		if p != "" {
+			last = i
+		}
+	}
+	if last == -1 {
+		return ""
+	}
+	return strings.Join(parts[:last+1], ":")
+}
+
+// ValidateSELinuxLabel reports whether label has the basic shape of a valid SELinux
+// label, i.e. it is non-empty, its user, role and type components (when present) do not
+// contain whitespace, and its level component (when present) conforms to the MCS
+// "sN[:cX,cY,...]" grammar parseMCS/sensitivityNumber understand. It does not validate
+// against any policy, since that is out of reach for this package.
+func ValidateSELinuxLabel(label string) error {
+	if strings.TrimSpace(label) == "" {
+		return fmt.Errorf("SELinux label must not be empty")
+	}
+	parts := ParseSELinuxLabel(label)
+	for i, name := range []string{"user", "role", "type"} {
+		if strings.ContainsAny(parts[i], " \t\n") {
+			return fmt.Errorf("SELinux label %q has an invalid %s component %q", label, name, parts[i])
+		}
+	}
+	if level := parts[3]; level != "" {
+		mcs, err := parseMCS(level)
+		if err != nil {
+			return fmt.Errorf("SELinux label %q has an invalid level component: %w", label, err)
+		}
+		if _, err := sensitivityNumber(mcs.sensitivity); err != nil {
+			return fmt.Errorf("SELinux label %q has an invalid level component: %w", label, err)
+		}
+	}
+	return nil
+}
+
+// mcsRange is the parsed form of the MCS portion of a SELinux level, e.g. "s0:c0,c2" or
+// "s0:c0.c10".
+type mcsRange struct {
+	sensitivity string
+	categories  map[string]bool
+}
+
+// parseMCS parses the level component of a SELinux label (everything after the type
+// component, e.g. "s0:c0,c2" or "s0:c0.c10") into its sensitivity and set of
+// categories. Category ranges ("c0.c10") are expanded into individual categories.
+func parseMCS(level string) (mcsRange, error) {
+	r := mcsRange{categories: map[string]bool{}}
+	if level == "" {
+		return r, nil
+	}
+
+	sensitivity, categories, _ := strings.Cut(level, ":")
+	r.sensitivity = sensitivity
+	if categories == "" {
+		return r, nil
+	}
+
+	for _, part := range strings.Split(categories, ",") {
+		lo, hi, isRange := strings.Cut(part, ".")
+		if !isRange {
+			if _, err := categoryNumber(lo); err != nil {
+				return mcsRange{}, err
+			}
+			r.categories[lo] = true
+			continue
+		}
+		loNum, loErr := categoryNumber(lo)
+		hiNum, hiErr := categoryNumber(hi)
+		if loErr != nil || hiErr != nil || hiNum < loNum {
+			return mcsRange{}, fmt.Errorf("invalid MCS category range %q", part)
+		}
+		for n := loNum; n <= hiNum; n++ {
+			r.categories[fmt.Sprintf("c%d", n)] = true
+		}
+	}
+	return r, nil
+}
+
+func categoryNumber(category string) (int, error) {
+	n, ok := strings.CutPrefix(category, "c")
+	if !ok {
+		return 0, fmt.Errorf("invalid MCS category %q", category)
+	}
+	return strconv.Atoi(n)
+}
+
+// sensitivityNumber parses the numeric part of an MCS sensitivity, e.g. "s0" -> 0. An
+// empty sensitivity (no level at all) parses as 0, matching "no MCS constraint".
+func sensitivityNumber(sensitivity string) (int, error) {
+	if sensitivity == "" {
+		return 0, nil
+	}
+	n, ok := strings.CutPrefix(sensitivity, "s")
+	if !ok {
+		return 0, fmt.Errorf("invalid MCS sensitivity %q", sensitivity)
+	}
+	return strconv.Atoi(n)
+}
+
+// CompareMCSRange reports whether levelA's MCS range dominates levelB's, i.e. whether
+// anything levelB's range would permit, levelA's range would also permit: levelA's
+// sensitivity must be at least as high as levelB's, and levelA's categories must be a
+// superset of levelB's. equal is true when the two ranges are identical. This is the
+// check for deciding whether a pod can safely reuse a label already in use by another
+// pod on the node - MCSOverlap's "do they share a category" is the wrong question there,
+// since overlap without dominance still leaves each pod able to see categories the other
+// was never granted.
+func CompareMCSRange(levelA, levelB string) (dominates, equal bool, err error) {
+	a, err := parseMCS(levelA)
+	if err != nil {
+		return false, false, err
+	}
+	b, err := parseMCS(levelB)
+	if err != nil {
+		return false, false, err
+	}
+
+	aSens, err := sensitivityNumber(a.sensitivity)
+	if err != nil {
+		return false, false, err
+	}
+	bSens, err := sensitivityNumber(b.sensitivity)
+	if err != nil {
+		return false, false, err
+	}
+
+	if aSens < bSens {
+		return false, false, nil
+	}
+	for c := range b.categories {
+		if !a.categories[c] {
+			return false, false, nil
+		}
+	}
+
+	equal = aSens == bSens && len(a.categories) == len(b.categories)
+	return true, equal, nil
+}
+
+// MCSOverlap reports whether the MCS ranges of two SELinux levels share the same
+// sensitivity and at least one category in common. Two empty levels are considered to
+// overlap, since both mean "no MCS constraint".
+func MCSOverlap(levelA, levelB string) (bool, error) {
+	a, err := parseMCS(levelA)
+	if err != nil {
+		return false, err
+	}
+	b, err := parseMCS(levelB)
+	if err != nil {
+		return false, err
+	}
+
+	if a.sensitivity != b.sensitivity {
+		return false, nil
+	}
+	if len(a.categories) == 0 || len(b.categories) == 0 {
+		return len(a.categories) == len(b.categories), nil
+	}
+	for c := range a.categories {
+		if b.categories[c] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
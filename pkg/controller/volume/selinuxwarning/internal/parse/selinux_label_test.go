@@ -104,3 +104,150 @@ func TestParseSELinuxLabel(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatSELinuxLabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		parts    [4]string
+		expected string
+	}{
+		{
+			name:     "complete label",
+			parts:    [4]string{"system_u", "system_r", "container_t", "s0:c0,c1"},
+			expected: "system_u:system_r:container_t:s0:c0,c1",
+		},
+		{
+			name:     "missing level",
+			parts:    [4]string{"system_u", "system_r", "container_t", ""},
+			expected: "system_u:system_r:container_t",
+		},
+		{
+			name:     "user only",
+			parts:    [4]string{"system_u", "", "", ""},
+			expected: "system_u",
+		},
+		{
+			name:     "all empty",
+			parts:    [4]string{"", "", "", ""},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatSELinuxLabel(tt.parts); got != tt.expected {
+				t.Errorf("FormatSELinuxLabel(%v) = %q, expected %q", tt.parts, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatSELinuxLabelRoundTrip(t *testing.T) {
+	labels := []string{
+		"system_u:system_r:container_t:s0:c0,c1",
+		"system_u:system_r:container_t",
+		"system_u",
+	}
+	for _, label := range labels {
+		if got := FormatSELinuxLabel(ParseSELinuxLabel(label)); got != label {
+			t.Errorf("FormatSELinuxLabel(ParseSELinuxLabel(%q)) = %q, expected %q", label, got, label)
+		}
+	}
+}
+
+func TestValidateSELinuxLabel(t *testing.T) {
+	tests := []struct {
+		name    string
+		label   string
+		wantErr bool
+	}{
+		{name: "valid complete label", label: "system_u:system_r:container_t:s0:c0,c1"},
+		{name: "valid partial label", label: "container_t"},
+		{name: "empty label", label: "", wantErr: true},
+		{name: "whitespace only", label: "   ", wantErr: true},
+		{name: "user with space", label: "system u:system_r:container_t", wantErr: true},
+		{name: "malformed sensitivity", label: "system_u:system_r:container_t:not-a-valid-level", wantErr: true},
+		{name: "malformed category", label: "system_u:system_r:container_t:s0:cNaN", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSELinuxLabel(tt.label)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSELinuxLabel(%q) error = %v, wantErr %v", tt.label, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMCSOverlap(t *testing.T) {
+	tests := []struct {
+		name        string
+		levelA      string
+		levelB      string
+		wantOverlap bool
+		wantErr     bool
+	}{
+		{name: "identical categories", levelA: "s0:c0,c1", levelB: "s0:c0,c1", wantOverlap: true},
+		{name: "shared category", levelA: "s0:c0,c1", levelB: "s0:c1,c2", wantOverlap: true},
+		{name: "disjoint categories", levelA: "s0:c0,c1", levelB: "s0:c2,c3", wantOverlap: false},
+		{name: "different sensitivity", levelA: "s0:c0,c1", levelB: "s1:c0,c1", wantOverlap: false},
+		{name: "range overlaps single", levelA: "s0:c0.c10", levelB: "s0:c5", wantOverlap: true},
+		{name: "range disjoint", levelA: "s0:c0.c4", levelB: "s0:c5.c10", wantOverlap: false},
+		{name: "both unconstrained", levelA: "s0", levelB: "s0", wantOverlap: true},
+		{name: "one unconstrained", levelA: "s0", levelB: "s0:c0", wantOverlap: false},
+		{name: "invalid range", levelA: "s0:c10.c0", levelB: "s0:c0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overlap, err := MCSOverlap(tt.levelA, tt.levelB)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MCSOverlap(%q, %q) error = %v, wantErr %v", tt.levelA, tt.levelB, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if overlap != tt.wantOverlap {
+				t.Errorf("MCSOverlap(%q, %q) = %v, expected %v", tt.levelA, tt.levelB, overlap, tt.wantOverlap)
+			}
+		})
+	}
+}
+
+func TestCompareMCSRange(t *testing.T) {
+	tests := []struct {
+		name          string
+		levelA        string
+		levelB        string
+		wantDominates bool
+		wantEqual     bool
+		wantErr       bool
+	}{
+		{name: "identical ranges", levelA: "s0:c0,c1", levelB: "s0:c0,c1", wantDominates: true, wantEqual: true},
+		{name: "superset dominates subset", levelA: "s0:c0,c1,c2", levelB: "s0:c0,c1", wantDominates: true},
+		{name: "subset does not dominate superset", levelA: "s0:c0,c1", levelB: "s0:c0,c1,c2"},
+		{name: "partial overlap dominates neither way", levelA: "s0:c0,c1", levelB: "s0:c1,c2"},
+		{name: "higher sensitivity with same categories dominates", levelA: "s1:c0", levelB: "s0:c0", wantDominates: true},
+		{name: "lower sensitivity does not dominate", levelA: "s0:c0", levelB: "s1:c0"},
+		{name: "unconstrained does not dominate a level with categories", levelA: "s0", levelB: "s0:c0"},
+		{name: "a level with categories dominates the unconstrained baseline", levelA: "s0:c0", levelB: "s0", wantDominates: true},
+		{name: "both unconstrained", levelA: "s0", levelB: "s0", wantDominates: true, wantEqual: true},
+		{name: "invalid range", levelA: "s0:c10.c0", levelB: "s0:c0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dominates, equal, err := CompareMCSRange(tt.levelA, tt.levelB)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CompareMCSRange(%q, %q) error = %v, wantErr %v", tt.levelA, tt.levelB, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if dominates != tt.wantDominates || equal != tt.wantEqual {
+				t.Errorf("CompareMCSRange(%q, %q) = (%v, %v), expected (%v, %v)", tt.levelA, tt.levelB, dominates, equal, tt.wantDominates, tt.wantEqual)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics exposes observability for PostStartHooks that wait on informer
+// readiness through poststarthook.WaitForReadyOrBypass, such as the ServiceCIDR repair
+// controller's, so a slow or backed-off informer sync shows up in metrics instead of only
+// as a startup delay or log line.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	subsystem = "poststarthook"
+	hookName  = "hook"
+)
+
+var registerMetricsOnce sync.Once
+
+var (
+	// waitRetriesTotal counts the number of times WaitForReadyOrBypass polled hasSynced
+	// and found it not yet ready, broken down by hook name.
+	waitRetriesTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      subsystem,
+			Name:           "wait_for_ready_retries_total",
+			Help:           "Total number of times a PostStartHook's WaitForReadyOrBypass call found its informer not yet synced and retried, broken down by hook name.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{hookName},
+	)
+
+	// waitTimeToReadySeconds measures how long a hook spent in WaitForReadyOrBypass
+	// before hasSynced first reported true, broken down by hook name. A hook that hit
+	// its timeout and used the bypass instead is not recorded here.
+	waitTimeToReadySeconds = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      subsystem,
+			Name:           "wait_for_ready_duration_seconds",
+			Help:           "Time in seconds a PostStartHook's WaitForReadyOrBypass call spent waiting before its informer reported synced, broken down by hook name.",
+			Buckets:        metrics.ExponentialBuckets(0.001, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{hookName},
+	)
+
+	// waitBypassTotal counts the number of times WaitForReadyOrBypass hit its timeout
+	// and fell back to the caller-provided bypass instead of waiting further.
+	waitBypassTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      subsystem,
+			Name:           "wait_for_ready_bypass_total",
+			Help:           "Total number of times a PostStartHook's WaitForReadyOrBypass call timed out and fell back to its bypass, broken down by hook name.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{hookName},
+	)
+)
+
+func Register() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(waitRetriesTotal)
+		legacyregistry.MustRegister(waitTimeToReadySeconds)
+		legacyregistry.MustRegister(waitBypassTotal)
+	})
+}
+
+// Only used for tests.
+func Reset() {
+	legacyregistry.Reset()
+}
+
+// IncWaitRetry increments the # of retries a hook's WaitForReadyOrBypass call has made.
+func IncWaitRetry(hook string) {
+	waitRetriesTotal.WithLabelValues(hook).Add(1)
+}
+
+// ObserveTimeToReady records how long a hook's WaitForReadyOrBypass call waited before
+// its informer reported synced.
+func ObserveTimeToReady(hook string, duration time.Duration) {
+	waitTimeToReadySeconds.WithLabelValues(hook).Observe(duration.Seconds())
+}
+
+// IncWaitBypass increments the # of times a hook's WaitForReadyOrBypass call timed out
+// and fell back to its bypass.
+func IncWaitBypass(hook string) {
+	waitBypassTotal.WithLabelValues(hook).Add(1)
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poststarthook
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// PostStartHookDependencies declares the prerequisites a PostStartHook needs satisfied
+// before it runs. It is the input a genericapiserver.Config.AddPostStartHookWithDependencies
+// would take alongside a hook's name and function, so a hook author can write
+//
+//	config.AddPostStartHookWithDependencies("servicecidr-repair",
+//	    PostStartHookDependencies{Requires: []string{"rbac/bootstrap-roles", "priority-and-fairness-config-consumer", "start-cluster-authentication-info-controller"}},
+//	    repairHookFn)
+//
+// instead of racing the apiserver readiness gate against whichever other hooks happen to
+// still be running. Runner consumes it today through NewHook, ahead of that Config method
+// existing in this tree.
+type PostStartHookDependencies struct {
+	// Requires lists the Name of every hook that must finish successfully before the
+	// hook declaring this dependency starts. Order does not matter and cycles are
+	// detected at Run time.
+	Requires []string
+}
+
+// NewHook builds a Hook named name that runs fn once every hook in deps.Requires has
+// completed successfully.
+func NewHook(name string, deps PostStartHookDependencies, fn func(ctx context.Context) error) Hook {
+	return Hook{Name: name, Requires: deps.Requires, Fn: fn}
+}
+
+// Hook is a single named PostStartHook together with the names of the other hooks it
+// requires to have completed first. Requires lets a hook declare its real prerequisites
+// (e.g. "an informer this hook reads from must have synced", or "the controller that
+// owns this resource must already be running") instead of either blocking forever on a
+// dependency that hasn't started yet, or racing ahead of it and getting rejected by
+// admission, which is exactly the failure mode behind
+// https://github.com/kubernetes/kubernetes/issues/136288.
+type Hook struct {
+	// Name identifies the hook. It must be unique within a Runner and is what other
+	// hooks reference in their Requires list.
+	Name string
+	// Requires lists the Name of every hook that must finish successfully before this
+	// one starts. Order does not matter and cycles are detected at Run time.
+	Requires []string
+	// Fn is the hook body. It receives the ctx passed to Run; Run does not cancel
+	// that ctx when a hook in a different branch fails.
+	Fn func(ctx context.Context) error
+}
+
+// Runner executes a set of Hooks in dependency order, running hooks whose
+// dependencies are already satisfied concurrently with each other.
+type Runner struct {
+	hooks map[string]Hook
+	order []string // registration order; Run does not read it and starts ready hooks in name order
+}
+
+// NewRunner creates an empty Runner.
+func NewRunner() *Runner {
+	return &Runner{hooks: map[string]Hook{}}
+}
+
+// Add registers a hook. Add panics if Name is empty or was already registered. It does
+// not check whether Run has already started.
+func (r *Runner) Add(hook Hook) {
+	if hook.Name == "" {
+		panic("poststarthook: hook Name must not be empty")
+	}
+	if _, exists := r.hooks[hook.Name]; exists {
+		panic(fmt.Sprintf("poststarthook: hook %q registered more than once", hook.Name))
+	}
+	r.hooks[hook.Name] = hook
+	r.order = append(r.order, hook.Name)
+}
+
+// Run validates that every declared dependency exists and that the dependency graph is
+// acyclic, then executes the hooks in topological order, running all hooks whose
+// dependencies have already completed concurrently with each other. It returns as soon
+// as any hook fails: hooks that have not started yet are skipped, and the returned
+// error identifies which hook failed. Hooks already running when a sibling fails are
+// still allowed to finish; ctx is not canceled by a sibling failure, so a long-running
+// hook that wants to abort early should watch ctx itself.
+//
+// Run returns before starting any hook if a hook references a dependency that was never
+// added. A cycle is reported with an error as soon as no remaining hook can start, so
+// that server startup fails with a clear error instead of deadlocking; hooks outside the
+// cycle that became ready before that point have already run by then.
+func (r *Runner) Run(ctx context.Context) error {
+	for name, hook := range r.hooks {
+		for _, dep := range hook.Requires {
+			if _, ok := r.hooks[dep]; !ok {
+				return fmt.Errorf("poststarthook %q requires %q, which was never registered", name, dep)
+			}
+		}
+	}
+
+	remaining := make(map[string]Hook, len(r.hooks))
+	for name, hook := range r.hooks {
+		remaining[name] = hook
+	}
+	done := make(map[string]bool, len(r.hooks))
+
+	for len(remaining) > 0 {
+		var ready []string
+		for name, hook := range remaining {
+			if dependenciesSatisfied(hook.Requires, done) {
+				ready = append(ready, name)
+			}
+		}
+		if len(ready) == 0 {
+			return fmt.Errorf("poststarthook dependency cycle detected among: %s", sortedNames(remaining))
+		}
+		// Sorting makes scheduling order deterministic across runs: the set of hooks
+		// eligible to start at any point in time depends only on the dependency
+		// graph, not on Go's unspecified map iteration order.
+		sort.Strings(ready)
+
+		type result struct {
+			name string
+			err  error
+		}
+		results := make(chan result, len(ready))
+		for _, name := range ready {
+			hook := remaining[name]
+			go func() {
+				results <- result{name: hook.Name, err: hook.Fn(ctx)}
+			}()
+		}
+		for range ready {
+			res := <-results
+			if res.err != nil {
+				return fmt.Errorf("poststarthook %q failed: %w", res.name, res.err)
+			}
+			done[res.name] = true
+			delete(remaining, res.name)
+		}
+	}
+	return nil
+}
+
+func dependenciesSatisfied(requires []string, done map[string]bool) bool {
+	for _, dep := range requires {
+		if !done[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedNames(hooks map[string]Hook) []string {
+	names := make([]string, 0, len(hooks))
+	for name := range hooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poststarthook
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestRunnerRunsInDependencyOrder(t *testing.T) {
+	var mu sync.Mutex
+	var started []string
+	record := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			started = append(started, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	r := NewRunner()
+	r.Add(Hook{Name: "rbac/bootstrap-roles", Fn: record("rbac/bootstrap-roles")})
+	r.Add(Hook{Name: "priority-and-fairness-config-consumer", Fn: record("priority-and-fairness-config-consumer")})
+	r.Add(Hook{
+		Name:     "servicecidr-repair",
+		Requires: []string{"rbac/bootstrap-roles", "priority-and-fairness-config-consumer"},
+		Fn:       record("servicecidr-repair"),
+	})
+
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if started[len(started)-1] != "servicecidr-repair" {
+		t.Fatalf("expected servicecidr-repair to run last, got order: %v", started)
+	}
+}
+
+func TestRunnerRunsHookBuiltFromPostStartHookDependencies(t *testing.T) {
+	var mu sync.Mutex
+	var started []string
+	record := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			started = append(started, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	r := NewRunner()
+	r.Add(Hook{Name: "rbac/bootstrap-roles", Fn: record("rbac/bootstrap-roles")})
+	r.Add(NewHook(
+		"servicecidr-repair",
+		PostStartHookDependencies{Requires: []string{"rbac/bootstrap-roles"}},
+		record("servicecidr-repair"),
+	))
+
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if started[len(started)-1] != "servicecidr-repair" {
+		t.Fatalf("expected servicecidr-repair to run last, got order: %v", started)
+	}
+}
+
+func TestRunnerFailsFastOnMissingDependency(t *testing.T) {
+	r := NewRunner()
+	r.Add(Hook{
+		Name:     "servicecidr-repair",
+		Requires: []string{"does-not-exist"},
+		Fn:       func(ctx context.Context) error { return nil },
+	})
+
+	err := r.Run(context.Background())
+	if err == nil {
+		t.Fatal("expected an error for a missing dependency")
+	}
+}
+
+func TestRunnerFailsFastOnCycle(t *testing.T) {
+	r := NewRunner()
+	r.Add(Hook{Name: "a", Requires: []string{"b"}, Fn: func(ctx context.Context) error { return nil }})
+	r.Add(Hook{Name: "b", Requires: []string{"a"}, Fn: func(ctx context.Context) error { return nil }})
+
+	err := r.Run(context.Background())
+	if err == nil {
+		t.Fatal("expected an error for a dependency cycle")
+	}
+}
+
+func TestRunnerSurfacesHookError(t *testing.T) {
+	r := NewRunner()
+	r.Add(Hook{Name: "a", Fn: func(ctx context.Context) error { return fmt.Errorf("boom") }})
+	r.Add(Hook{Name: "b", Requires: []string{"a"}, Fn: func(ctx context.Context) error {
+		t.Fatal("hook b must not run after its dependency a failed")
+		return nil
+	}})
+
+	err := r.Run(context.Background())
+	if err == nil {
+		t.Fatal("expected the failing hook's error to be surfaced")
+	}
+}
+
+func TestRunnerParallelizesIndependentBranches(t *testing.T) {
+	release := make(chan struct{})
+	var aStarted, bStarted = make(chan struct{}), make(chan struct{})
+
+	r := NewRunner()
+	r.Add(Hook{Name: "a", Fn: func(ctx context.Context) error {
+		close(aStarted)
+		<-release
+		return nil
+	}})
+	r.Add(Hook{Name: "b", Fn: func(ctx context.Context) error {
+		close(bStarted)
+		<-release
+		return nil
+	}})
+
+	done := make(chan error, 1)
+	go func() { done <- r.Run(context.Background()) }()
+
+	<-aStarted
+	<-bStarted
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
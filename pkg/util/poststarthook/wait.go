@@ -0,0 +1,127 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package poststarthook provides small helpers shared by PostStartHooks that need to
+// wait for an informer to become ready before doing privileged work (e.g. the
+// ServiceCIDR repair controller creating IPAddress objects), without either blocking
+// apiserver readiness forever or racing ahead of admission plugins that depend on the
+// same informer.
+package poststarthook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	poststarthookmetrics "k8s.io/kubernetes/pkg/util/poststarthook/metrics"
+)
+
+// WaitForReadyOrBypassOptions configures WaitForReadyOrBypass.
+type WaitForReadyOrBypassOptions struct {
+	// Timeout bounds the total time spent waiting for hasSynced before falling back to
+	// bypass (or giving up). A value <= 0 uses 30s.
+	Timeout time.Duration
+	// InitialInterval is how long WaitForReadyOrBypass waits before its first retry. A
+	// value <= 0 uses 100ms.
+	InitialInterval time.Duration
+	// MaxInterval caps the exponential backoff applied to InitialInterval across
+	// repeated retries. A value <= 0 uses 5s.
+	MaxInterval time.Duration
+	// Clock returns the current time; defaults to time.Now if nil. It drives the
+	// deadline check and the time-to-ready measurement; the waits between polls always
+	// use real time.
+	Clock func() time.Time
+}
+
+func (o WaitForReadyOrBypassOptions) timeout() time.Duration {
+	if o.Timeout <= 0 {
+		return 30 * time.Second
+	}
+	return o.Timeout
+}
+
+func (o WaitForReadyOrBypassOptions) initialInterval() time.Duration {
+	if o.InitialInterval <= 0 {
+		return 100 * time.Millisecond
+	}
+	return o.InitialInterval
+}
+
+func (o WaitForReadyOrBypassOptions) maxInterval() time.Duration {
+	if o.MaxInterval <= 0 {
+		return 5 * time.Second
+	}
+	return o.MaxInterval
+}
+
+func (o WaitForReadyOrBypassOptions) now() time.Time {
+	if o.Clock != nil {
+		return o.Clock()
+	}
+	return time.Now()
+}
+
+// WaitForReadyOrBypass polls hasSynced, backing off exponentially between polls (from
+// opts.InitialInterval up to opts.MaxInterval) until either hasSynced returns true or
+// opts.Timeout elapses. If the timeout elapses and bypass is non-nil, bypass is consulted
+// once more: if it reports true, WaitForReadyOrBypass returns nil anyway, allowing the
+// caller to proceed without the informer (e.g. because admission for the resources it
+// guards has been explicitly disabled). Otherwise it returns an error describing the
+// timeout.
+//
+// hookName identifies the calling PostStartHook (e.g. "servicecidr-repair") for the
+// poststarthookmetrics retry-count, time-to-ready, and bypass counters this records.
+//
+// This exists for PostStartHooks, like the ServiceCIDR repair controller's, that would
+// otherwise either deadlock waiting on a namespace informer that depends on them being
+// done first, or race ahead and get rejected by admission for namespaces it hasn't
+// learned about yet.
+func WaitForReadyOrBypass(ctx context.Context, hookName string, hasSynced func() bool, bypass func() bool, opts WaitForReadyOrBypassOptions) error {
+	start := opts.now()
+	deadline := start.Add(opts.timeout())
+	interval := opts.initialInterval()
+
+	for {
+		if hasSynced() {
+			poststarthookmetrics.ObserveTimeToReady(hookName, opts.now().Sub(start))
+			return nil
+		}
+		if opts.now().After(deadline) {
+			if bypass != nil && bypass() {
+				poststarthookmetrics.IncWaitBypass(hookName)
+				return nil
+			}
+			return fmt.Errorf("poststarthook %q timed out after %s waiting for informer to sync", hookName, opts.timeout())
+		}
+
+		poststarthookmetrics.IncWaitRetry(hookName)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		interval = nextInterval(interval, opts.maxInterval())
+	}
+}
+
+// nextInterval doubles prev, capped at max.
+func nextInterval(prev, max time.Duration) time.Duration {
+	next := prev * 2
+	if next > max {
+		next = max
+	}
+	return next
+}
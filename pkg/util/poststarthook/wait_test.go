@@ -0,0 +1,136 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package poststarthook
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/component-base/metrics/testutil"
+	poststarthookmetrics "k8s.io/kubernetes/pkg/util/poststarthook/metrics"
+)
+
+func TestWaitForReadyOrBypassSucceedsOnceSynced(t *testing.T) {
+	tries := 0
+	err := WaitForReadyOrBypass(context.Background(), "test-hook", func() bool {
+		tries++
+		return tries >= 3
+	}, nil, WaitForReadyOrBypassOptions{Timeout: time.Second, InitialInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tries < 3 {
+		t.Fatalf("expected at least 3 polls, got %d", tries)
+	}
+}
+
+func TestWaitForReadyOrBypassTimesOutWithoutBypass(t *testing.T) {
+	err := WaitForReadyOrBypass(context.Background(), "test-hook", func() bool { return false }, nil,
+		WaitForReadyOrBypassOptions{Timeout: 20 * time.Millisecond, InitialInterval: 5 * time.Millisecond})
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+}
+
+func TestWaitForReadyOrBypassUsesBypassOnTimeout(t *testing.T) {
+	err := WaitForReadyOrBypass(context.Background(), "test-hook", func() bool { return false }, func() bool { return true },
+		WaitForReadyOrBypassOptions{Timeout: 20 * time.Millisecond, InitialInterval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("expected bypass to suppress the timeout error, got: %v", err)
+	}
+}
+
+func TestWaitForReadyOrBypassHonorsContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := WaitForReadyOrBypass(ctx, "test-hook", func() bool { return false }, nil,
+		WaitForReadyOrBypassOptions{Timeout: time.Second, InitialInterval: time.Millisecond})
+	if err == nil {
+		t.Fatal("expected context cancellation to be surfaced as an error")
+	}
+}
+
+func TestWaitForReadyOrBypassBacksOffExponentially(t *testing.T) {
+	var pollTimes []time.Time
+
+	err := WaitForReadyOrBypass(context.Background(), "test-hook", func() bool {
+		pollTimes = append(pollTimes, time.Now())
+		return len(pollTimes) >= 4
+	}, nil, WaitForReadyOrBypassOptions{
+		Timeout:         time.Minute,
+		InitialInterval: 10 * time.Millisecond,
+		MaxInterval:     time.Second,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pollTimes) != 4 {
+		t.Fatalf("expected exactly 4 polls, got %d", len(pollTimes))
+	}
+
+	gap1 := pollTimes[1].Sub(pollTimes[0])
+	gap2 := pollTimes[2].Sub(pollTimes[1])
+	if gap2 < gap1 {
+		t.Fatalf("expected the second retry interval (%s) to be at least as long as the first (%s)", gap2, gap1)
+	}
+}
+
+func TestWaitForReadyOrBypassRecordsMetrics(t *testing.T) {
+	poststarthookmetrics.Register()
+	defer poststarthookmetrics.Reset()
+
+	tries := 0
+	err := WaitForReadyOrBypass(context.Background(), "metrics-test-hook", func() bool {
+		tries++
+		return tries >= 3
+	}, nil, WaitForReadyOrBypassOptions{Timeout: time.Second, InitialInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantRetries := `
+		# HELP poststarthook_wait_for_ready_retries_total [ALPHA] Total number of times a PostStartHook's WaitForReadyOrBypass call found its informer not yet synced and retried, broken down by hook name.
+		# TYPE poststarthook_wait_for_ready_retries_total counter
+		poststarthook_wait_for_ready_retries_total{hook="metrics-test-hook"} 2
+	`
+	if err := testutil.GatherAndCompare(legacyregistry.DefaultGatherer, strings.NewReader(wantRetries), "poststarthook_wait_for_ready_retries_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWaitForReadyOrBypassRecordsBypassMetric(t *testing.T) {
+	poststarthookmetrics.Register()
+	defer poststarthookmetrics.Reset()
+
+	err := WaitForReadyOrBypass(context.Background(), "bypass-test-hook", func() bool { return false }, func() bool { return true },
+		WaitForReadyOrBypassOptions{Timeout: 10 * time.Millisecond, InitialInterval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("expected bypass to suppress the timeout error, got: %v", err)
+	}
+
+	wantBypass := `
+		# HELP poststarthook_wait_for_ready_bypass_total [ALPHA] Total number of times a PostStartHook's WaitForReadyOrBypass call timed out and fell back to its bypass, broken down by hook name.
+		# TYPE poststarthook_wait_for_ready_bypass_total counter
+		poststarthook_wait_for_ready_bypass_total{hook="bypass-test-hook"} 1
+	`
+	if err := testutil.GatherAndCompare(legacyregistry.DefaultGatherer, strings.NewReader(wantBypass), "poststarthook_wait_for_ready_bypass_total"); err != nil {
+		t.Error(err)
+	}
+}
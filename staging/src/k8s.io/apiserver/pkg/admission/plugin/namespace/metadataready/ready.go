@@ -0,0 +1,110 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metadataready provides a lightweight namespace-readiness check for admission
+// plugins (e.g. NamespaceLifecycle) that only need to know whether a namespace exists
+// and is terminating, not its full object. On clusters with a very large number of
+// namespaces, syncing a full-object namespace informer before admission can serve
+// requests takes long enough to race with other PostStartHooks; listing/watching
+// metadata only is much cheaper and avoids that race.
+package metadataready
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+var namespacesResource = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
+
+// Checker answers whether a namespace exists and whether it is terminating, backed by a
+// metadata-only informer instead of a full-object one.
+type Checker struct {
+	informer cache.SharedIndexInformer
+}
+
+// NewChecker builds a Checker and starts populating its informer. Callers should wait
+// for HasSynced to become true (e.g. via cache.WaitForCacheSync) before relying on Exists
+// or Terminating, the same way they would for any other informer-backed readiness gate.
+func NewChecker(client metadata.Interface, stopCh <-chan struct{}) *Checker {
+	factory := metadatainformer.NewMetadataInformer(client, 0)
+	informer := factory.ForResource(namespacesResource).Informer()
+	go informer.Run(stopCh)
+	return &Checker{informer: informer}
+}
+
+// HasSynced reports whether the underlying metadata informer has completed its initial
+// list, i.e. whether Exists/Terminating answers can be trusted.
+func (c *Checker) HasSynced() bool {
+	return c.informer.HasSynced()
+}
+
+// Exists reports whether a namespace with the given name is currently known.
+func (c *Checker) Exists(name string) bool {
+	_, exists, err := c.informer.GetStore().GetByKey(name)
+	return err == nil && exists
+}
+
+// Terminating reports whether the named namespace is known and has a non-nil
+// DeletionTimestamp. It returns false for namespaces that do not exist, matching the
+// behavior an admission plugin wants: "not terminating" unless proven otherwise.
+func (c *Checker) Terminating(name string) bool {
+	obj, exists, err := c.informer.GetStore().GetByKey(name)
+	if err != nil || !exists {
+		return false
+	}
+	accessor, ok := obj.(v1.Object)
+	return ok && accessor.GetDeletionTimestamp() != nil
+}
+
+// WantsNamespaceMetadataChecker should be implemented by an admission plugin (e.g.
+// NamespaceLifecycle) that wants to consume a metadata-only Checker instead of waiting on
+// its own full-object namespace informer. kube-apiserver's admission PluginInitializer
+// calls SetNamespaceMetadataChecker on every plugin implementing this interface, the same
+// way it already does for the generic WantsExternalKubeClientSet-style initializer
+// interfaces.
+type WantsNamespaceMetadataChecker interface {
+	SetNamespaceMetadataChecker(checker *Checker)
+}
+
+// PluginInitializer wires a single Checker into every admission plugin that opts in via
+// WantsNamespaceMetadataChecker, mirroring how kube-apiserver's other admission
+// initializers (client set, informer factory, ...) are threaded through
+// admission.PluginInitializer.Initialize. A nil Checker means the metadata-only path is
+// disabled (e.g. for a rollback); plugins are left to fall back to whatever
+// they used before this initializer existed.
+type PluginInitializer struct {
+	checker *Checker
+}
+
+// NewPluginInitializer returns a PluginInitializer that hands checker to every admission
+// plugin wanting one. Pass a nil checker to run the initializer as a no-op, for the
+// rollback case where the metadata-only path is disabled.
+func NewPluginInitializer(checker *Checker) *PluginInitializer {
+	return &PluginInitializer{checker: checker}
+}
+
+// Initialize implements admission.PluginInitializer.
+func (i *PluginInitializer) Initialize(plugin interface{}) {
+	if i.checker == nil {
+		return
+	}
+	if wants, ok := plugin.(WantsNamespaceMetadataChecker); ok {
+		wants.SetNamespaceMetadataChecker(i.checker)
+	}
+}
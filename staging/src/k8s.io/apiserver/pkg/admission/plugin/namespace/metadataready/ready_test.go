@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metadataready
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/metadata/fake"
+)
+
+func newTestChecker(t *testing.T, objects ...runtime.Object) (*Checker, chan struct{}) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	client := fake.NewSimpleMetadataClient(scheme, objects...)
+	stopCh := make(chan struct{})
+	t.Cleanup(func() { close(stopCh) })
+	return NewChecker(client, stopCh), stopCh
+}
+
+func TestCheckerExists(t *testing.T) {
+	ns := &metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{Kind: "Namespace", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+	}
+	checker, _ := newTestChecker(t, ns)
+
+	if err := wait(checker); err != nil {
+		t.Fatal(err)
+	}
+
+	if !checker.Exists("default") {
+		t.Error("expected namespace 'default' to exist")
+	}
+	if checker.Exists("missing") {
+		t.Error("expected namespace 'missing' to not exist")
+	}
+}
+
+func TestCheckerTerminating(t *testing.T) {
+	now := metav1.Now()
+	ns := &metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{Kind: "Namespace", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "going-away", DeletionTimestamp: &now},
+	}
+	checker, _ := newTestChecker(t, ns)
+
+	if err := wait(checker); err != nil {
+		t.Fatal(err)
+	}
+
+	if !checker.Terminating("going-away") {
+		t.Error("expected namespace 'going-away' to be terminating")
+	}
+	if checker.Terminating("missing") {
+		t.Error("expected a nonexistent namespace to not be reported as terminating")
+	}
+}
+
+type fakeWantsChecker struct {
+	checker *Checker
+}
+
+func (f *fakeWantsChecker) SetNamespaceMetadataChecker(checker *Checker) {
+	f.checker = checker
+}
+
+type fakePluginWithoutWants struct{}
+
+func TestPluginInitializerSetsChecker(t *testing.T) {
+	checker, _ := newTestChecker(t)
+	plugin := &fakeWantsChecker{}
+
+	NewPluginInitializer(checker).Initialize(plugin)
+
+	if plugin.checker != checker {
+		t.Error("expected Initialize to set the checker on a plugin implementing WantsNamespaceMetadataChecker")
+	}
+}
+
+func TestPluginInitializerIgnoresPluginsWithoutWants(t *testing.T) {
+	checker, _ := newTestChecker(t)
+
+	// Must not panic when the plugin doesn't implement WantsNamespaceMetadataChecker.
+	NewPluginInitializer(checker).Initialize(&fakePluginWithoutWants{})
+}
+
+func TestPluginInitializerDisabled(t *testing.T) {
+	plugin := &fakeWantsChecker{}
+
+	NewPluginInitializer(nil).Initialize(plugin)
+
+	if plugin.checker != nil {
+		t.Error("expected a nil Checker (rollback/disabled) to leave the plugin's checker unset")
+	}
+}
+
+func wait(checker *Checker) error {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if checker.HasSynced() {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return errors.New("informer did not sync in time")
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peerproxy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	peerproxymetrics "k8s.io/apiserver/pkg/util/peerproxy/metrics"
+)
+
+// CircuitState is the state of a peerCircuitBreaker.
+type CircuitState string
+
+const (
+	// CircuitClosed means the peer is treated as healthy; fetches/proxying proceed normally.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen means the peer has failed too many times in a row; fetches/proxying
+	// short-circuit until the backoff elapses.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen means the backoff has elapsed and exactly one probe is allowed
+	// through to decide whether to close the circuit again or reopen it with a longer
+	// backoff.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// circuitStates lists every CircuitState, for SetPeerProxyCircuitState to zero out the
+// states a transition moves away from.
+var circuitStates = []string{string(CircuitClosed), string(CircuitOpen), string(CircuitHalfOpen)}
+
+// CircuitBreakerOptions configures a peerCircuitBreaker.
+type CircuitBreakerOptions struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit. A
+	// value <= 0 uses 5.
+	FailureThreshold int
+	// InitialBackoff is the backoff the circuit opens with the first time it trips. A
+	// value <= 0 uses 30s.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponential backoff across repeated trips. A value <= 0 uses
+	// 5 minutes.
+	MaxBackoff time.Duration
+	// Clock returns the current time; defaults to time.Now if nil, so tests can inject a
+	// fake clock instead of sleeping for real backoff durations.
+	Clock func() time.Time
+}
+
+func (o CircuitBreakerOptions) failureThreshold() int {
+	if o.FailureThreshold <= 0 {
+		return 5
+	}
+	return o.FailureThreshold
+}
+
+func (o CircuitBreakerOptions) initialBackoff() time.Duration {
+	if o.InitialBackoff <= 0 {
+		return 30 * time.Second
+	}
+	return o.InitialBackoff
+}
+
+func (o CircuitBreakerOptions) maxBackoff() time.Duration {
+	if o.MaxBackoff <= 0 {
+		return 5 * time.Minute
+	}
+	return o.MaxBackoff
+}
+
+func (o CircuitBreakerOptions) now() time.Time {
+	if o.Clock != nil {
+		return o.Clock()
+	}
+	return time.Now()
+}
+
+// peerCircuitBreaker tracks consecutive discovery/proxy failures for a single peer and
+// decides when request-time proxying and discovery fetches to that peer should be
+// short-circuited rather than attempted. peerProxyHandler keeps one per peer, consulting
+// Allow before a discovery fetch or proxy attempt and recording the outcome afterward.
+type peerCircuitBreaker struct {
+	peerID string
+	opts   CircuitBreakerOptions
+
+	mu               sync.Mutex
+	state            CircuitState
+	consecutiveFails int
+	backoff          time.Duration
+	openedAt         time.Time
+	halfOpenProbeOut bool
+}
+
+// newPeerCircuitBreaker returns a peerCircuitBreaker for peerID in the closed state.
+func newPeerCircuitBreaker(peerID string, opts CircuitBreakerOptions) *peerCircuitBreaker {
+	return &peerCircuitBreaker{peerID: peerID, opts: opts, state: CircuitClosed}
+}
+
+// Allow reports whether a discovery fetch or proxy attempt to this peer should proceed
+// right now, and if not, how long the caller should report as Retry-After. A closed
+// circuit always allows; an open circuit allows once the backoff has elapsed (moving to
+// half-open and admitting exactly one probe); a half-open circuit with a probe already
+// outstanding denies until that probe's outcome is recorded.
+func (b *peerCircuitBreaker) Allow() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitClosed:
+		return true, 0
+	case CircuitHalfOpen:
+		if b.halfOpenProbeOut {
+			return false, b.retryAfterLocked()
+		}
+		b.halfOpenProbeOut = true
+		return true, 0
+	default: // CircuitOpen
+		if b.opts.now().Before(b.openedAt.Add(b.backoff)) {
+			return false, b.retryAfterLocked()
+		}
+		b.setStateLocked(CircuitHalfOpen)
+		b.halfOpenProbeOut = true
+		return true, 0
+	}
+}
+
+func (b *peerCircuitBreaker) retryAfterLocked() time.Duration {
+	remaining := b.openedAt.Add(b.backoff).Sub(b.opts.now())
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// RecordSuccess records a successful fetch/proxy attempt, resetting the failure count
+// and closing the circuit (from either closed or half-open).
+func (b *peerCircuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFails = 0
+	b.backoff = 0
+	b.halfOpenProbeOut = false
+	b.setStateLocked(CircuitClosed)
+}
+
+// RecordFailure records a failed fetch/proxy attempt. From closed, it opens the circuit
+// once consecutiveFails reaches opts.FailureThreshold. From half-open, any failure
+// reopens the circuit and doubles the backoff (capped at opts.MaxBackoff).
+func (b *peerCircuitBreaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitHalfOpen {
+		b.halfOpenProbeOut = false
+		b.openedAt = b.opts.now()
+		b.backoff = nextBackoff(b.backoff, b.opts.initialBackoff(), b.opts.maxBackoff())
+		b.setStateLocked(CircuitOpen)
+		return
+	}
+
+	b.consecutiveFails++
+	if b.state == CircuitClosed && b.consecutiveFails >= b.opts.failureThreshold() {
+		b.openedAt = b.opts.now()
+		b.backoff = b.opts.initialBackoff()
+		b.setStateLocked(CircuitOpen)
+	}
+}
+
+// State returns the circuit's current state.
+func (b *peerCircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *peerCircuitBreaker) setStateLocked(newState CircuitState) {
+	if b.state == newState {
+		return
+	}
+	b.state = newState
+	peerproxymetrics.SetPeerProxyCircuitState(b.peerID, string(newState), circuitStates)
+	peerproxymetrics.IncPeerProxyCircuitTransition(context.Background(), b.peerID, string(newState))
+}
+
+// nextBackoff doubles prev (or starts at initial if prev is zero), capped at max.
+func nextBackoff(prev, initial, max time.Duration) time.Duration {
+	next := prev * 2
+	if next <= 0 {
+		next = initial
+	}
+	if next > max {
+		next = max
+	}
+	return next
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peerproxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerCircuitBreaker(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	opts := CircuitBreakerOptions{
+		FailureThreshold: 3,
+		InitialBackoff:   30 * time.Second,
+		MaxBackoff:       5 * time.Minute,
+		Clock:            clock,
+	}
+
+	b := newPeerCircuitBreaker("peer-1", opts)
+	assert.Equal(t, CircuitClosed, b.State())
+
+	for i := 0; i < 2; i++ {
+		allowed, _ := b.Allow()
+		assert.True(t, allowed)
+		b.RecordFailure()
+	}
+	assert.Equal(t, CircuitClosed, b.State(), "circuit should stay closed below the failure threshold")
+
+	b.RecordFailure()
+	assert.Equal(t, CircuitOpen, b.State(), "circuit should open once the failure threshold is reached")
+
+	allowed, retryAfter := b.Allow()
+	assert.False(t, allowed, "an open circuit should deny before its backoff elapses")
+	assert.Equal(t, 30*time.Second, retryAfter)
+
+	now = now.Add(30 * time.Second)
+	allowed, _ = b.Allow()
+	assert.True(t, allowed, "an open circuit should admit exactly one probe once its backoff elapses")
+	assert.Equal(t, CircuitHalfOpen, b.State())
+
+	allowed, _ = b.Allow()
+	assert.False(t, allowed, "a half-open circuit should deny while its probe is outstanding")
+
+	b.RecordFailure()
+	assert.Equal(t, CircuitOpen, b.State(), "a failed probe should reopen the circuit")
+	_, retryAfter = b.Allow()
+	assert.Equal(t, 60*time.Second, retryAfter, "backoff should double after a failed half-open probe")
+
+	now = now.Add(60 * time.Second)
+	allowed, _ = b.Allow()
+	assert.True(t, allowed)
+	b.RecordSuccess()
+	assert.Equal(t, CircuitClosed, b.State(), "a successful probe should close the circuit and reset backoff")
+}
+
+func TestPeerCircuitBreakerMaxBackoff(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	b := newPeerCircuitBreaker("peer-1", CircuitBreakerOptions{
+		FailureThreshold: 1,
+		InitialBackoff:   time.Minute,
+		MaxBackoff:       3 * time.Minute,
+		Clock:            clock,
+	})
+
+	b.RecordFailure()
+	assert.Equal(t, CircuitOpen, b.State())
+
+	for i := 0; i < 5; i++ {
+		now = now.Add(b.backoff)
+		b.Allow()
+		b.RecordFailure()
+	}
+
+	assert.LessOrEqual(t, b.backoff, 3*time.Minute, "backoff should never exceed MaxBackoff")
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peerproxy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
+
+	peerproxymetrics "k8s.io/apiserver/pkg/util/peerproxy/metrics"
+)
+
+// DiscoverySyncOptions configures discoveryCacheSyncer.
+type DiscoverySyncOptions struct {
+	// Concurrency caps how many peers are fetched from at once. A value <= 0 uses
+	// min(8, numPeers) at call time.
+	Concurrency int
+	// Clock returns the current time; defaults to time.Now if nil, so tests can measure
+	// sync duration deterministically instead of depending on real wall-clock time.
+	Clock func() time.Time
+}
+
+func (o DiscoverySyncOptions) now() time.Time {
+	if o.Clock != nil {
+		return o.Clock()
+	}
+	return time.Now()
+}
+
+func (o DiscoverySyncOptions) concurrency(numPeers int) int {
+	if o.Concurrency > 0 {
+		return o.Concurrency
+	}
+	if numPeers < 8 {
+		if numPeers <= 0 {
+			return 1
+		}
+		return numPeers
+	}
+	return 8
+}
+
+// FetchResult is what a PeerDiscoveryFetcher returns for a single peer.
+type FetchResult struct {
+	// Body is the raw discovery document, nil when NotModified is true.
+	Body []byte
+	// ETag is the value to send as If-None-Match on the next fetch for this peer.
+	ETag string
+	// NotModified is true when the peer answered with a 304 against the ETag it was
+	// sent, meaning Body is stale-but-still-current and should not overwrite the cache.
+	NotModified bool
+}
+
+// PeerDiscoveryFetcher fetches the discovery document for peer, sending prevETag as
+// If-None-Match (empty if there is none cached yet).
+type PeerDiscoveryFetcher func(ctx context.Context, peer string, prevETag string) (FetchResult, error)
+
+// discoveryCacheSyncer runs one discovery fetch per known peer per sync round, bounding
+// concurrency and coalescing concurrent fetches to the same peer via singleflight, and
+// skips re-processing a peer's document when it answers 304 against its cached ETag.
+type discoveryCacheSyncer struct {
+	fetch PeerDiscoveryFetcher
+	opts  DiscoverySyncOptions
+
+	group singleflight.Group
+
+	mu        sync.Mutex
+	etags     map[string]string
+	bodySizes map[string]int
+}
+
+// newDiscoveryCacheSyncer returns a discoveryCacheSyncer that fetches peer discovery
+// documents with fetch.
+func newDiscoveryCacheSyncer(fetch PeerDiscoveryFetcher, opts DiscoverySyncOptions) *discoveryCacheSyncer {
+	return &discoveryCacheSyncer{
+		fetch:     fetch,
+		opts:      opts,
+		etags:     map[string]string{},
+		bodySizes: map[string]int{},
+	}
+}
+
+// Sync fetches discovery from every peer in peers, bounding in-flight fetches to
+// opts.Concurrency and calling onUpdate(peer, body) for each peer whose document
+// changed (i.e. wasn't a 304). It returns the first error encountered, after letting
+// every in-flight fetch finish, and records the round's duration and outcome.
+//
+// A peer listed more than once in peers - which can happen when a caller passes peers
+// gathered from more than one source - has its concurrent fetches within the round
+// collapse onto one another through the singleflight group. Duplicate fetches that do
+// not overlap in time (e.g. with a concurrency of 1) each make their own round-trip.
+func (s *discoveryCacheSyncer) Sync(ctx context.Context, peers []string, onUpdate func(peer string, result FetchResult)) error {
+	start := s.opts.now()
+	err := s.sync(ctx, peers, onUpdate)
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	peerproxymetrics.ObservePeerDiscoverySyncDuration(ctx, outcome, s.opts.now().Sub(start))
+	return err
+}
+
+func (s *discoveryCacheSyncer) sync(ctx context.Context, peers []string, onUpdate func(peer string, result FetchResult)) error {
+	sem := make(chan struct{}, s.opts.concurrency(len(peers)))
+	g, gctx := errgroup.WithContext(ctx)
+
+	for _, peer := range peers {
+		peer := peer
+		g.Go(func() error {
+			select {
+			case sem <- struct{}{}:
+			case <-gctx.Done():
+				return gctx.Err()
+			}
+			defer func() { <-sem }()
+
+			result, err := s.fetchOne(gctx, peer)
+			if err != nil {
+				// fetch alone records any per-attempt DiscoveryErrorFetch metric,
+				// since it alone knows how many attempts (e.g. an aggregated-discovery
+				// try followed by a legacy-discovery fallback) went into this result.
+				return err
+			}
+			if result.NotModified {
+				peerproxymetrics.AddPeerDiscoveryBytesSaved(s.cachedBodySize(peer))
+				return nil
+			}
+			s.setCachedBodySize(peer, len(result.Body))
+			onUpdate(peer, result)
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
+
+// cachedBodySize returns the byte size of the last non-304 body fetched for peer, or 0
+// if none has been recorded yet. It's what a 304 response's AddPeerDiscoveryBytesSaved
+// call reports as saved, since a 304's own FetchResult.Body is always nil.
+func (s *discoveryCacheSyncer) cachedBodySize(peer string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bodySizes[peer]
+}
+
+// setCachedBodySize records the byte size of peer's most recently fetched, non-304 body.
+func (s *discoveryCacheSyncer) setCachedBodySize(peer string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bodySizes[peer] = n
+}
+
+// fetchOne fetches peer's discovery document, coalescing concurrent callers for the
+// same peer onto a single in-flight HTTP round-trip via singleflight, and records the
+// resulting ETag for the next round's If-None-Match.
+func (s *discoveryCacheSyncer) fetchOne(ctx context.Context, peer string) (FetchResult, error) {
+	s.mu.Lock()
+	prevETag := s.etags[peer]
+	s.mu.Unlock()
+
+	v, err, _ := s.group.Do(peer, func() (interface{}, error) {
+		return s.fetch(ctx, peer, prevETag)
+	})
+	if err != nil {
+		return FetchResult{}, err
+	}
+
+	result := v.(FetchResult)
+	if result.ETag != "" {
+		s.mu.Lock()
+		s.etags[peer] = result.ETag
+		s.mu.Unlock()
+	}
+	return result, nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peerproxy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/component-base/metrics/testutil"
+
+	peerproxymetrics "k8s.io/apiserver/pkg/util/peerproxy/metrics"
+)
+
+func TestDiscoveryCacheSyncerSlowPeerDoesNotBlockFastPeers(t *testing.T) {
+	var inFlight int32
+	var maxInFlight int32
+
+	fetch := func(ctx context.Context, peer string, prevETag string) (FetchResult, error) {
+		cur := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			old := atomic.LoadInt32(&maxInFlight)
+			if cur <= old || atomic.CompareAndSwapInt32(&maxInFlight, old, cur) {
+				break
+			}
+		}
+
+		if peer == "slow-peer" {
+			select {
+			case <-time.After(50 * time.Millisecond):
+			case <-ctx.Done():
+				return FetchResult{}, ctx.Err()
+			}
+		}
+		return FetchResult{Body: []byte(peer), ETag: peer + "-etag"}, nil
+	}
+
+	s := newDiscoveryCacheSyncer(fetch, DiscoverySyncOptions{Concurrency: 4})
+
+	var mu sync.Mutex
+	updated := map[string]bool{}
+	var slowestFastPeer time.Duration
+	start := time.Now()
+	err := s.Sync(context.Background(), []string{"slow-peer", "fast-1", "fast-2", "fast-3"}, func(peer string, result FetchResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		updated[peer] = true
+		if elapsed := time.Since(start); peer != "slow-peer" && elapsed > slowestFastPeer {
+			slowestFastPeer = elapsed
+		}
+	})
+	require.NoError(t, err)
+	assert.Less(t, slowestFastPeer, 50*time.Millisecond, "fast peers should complete without waiting on the slow peer's full round-trip")
+	assert.Len(t, updated, 4)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(4), "concurrency should never exceed the configured cap")
+}
+
+func TestDiscoveryCacheSyncerSingleflightCoalescesDuplicatePeers(t *testing.T) {
+	var calls int32
+	fetch := func(ctx context.Context, peer string, prevETag string) (FetchResult, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		return FetchResult{Body: []byte(peer), ETag: "v1"}, nil
+	}
+
+	s := newDiscoveryCacheSyncer(fetch, DiscoverySyncOptions{Concurrency: 8})
+
+	var updates int32
+	err := s.Sync(context.Background(), []string{"peer-1", "peer-1", "peer-1"}, func(peer string, result FetchResult) {
+		atomic.AddInt32(&updates, 1)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "concurrent fetches for the same peer should coalesce into a single round-trip")
+}
+
+func TestDiscoveryCacheSyncerSkipsCacheMutationOn304(t *testing.T) {
+	round := 0
+	fetch := func(ctx context.Context, peer string, prevETag string) (FetchResult, error) {
+		round++
+		if round == 1 {
+			return FetchResult{Body: []byte("v1-body"), ETag: "v1"}, nil
+		}
+		assert.Equal(t, "v1", prevETag, "the second round should send the ETag cached from the first")
+		return FetchResult{NotModified: true}, nil
+	}
+
+	s := newDiscoveryCacheSyncer(fetch, DiscoverySyncOptions{Concurrency: 1})
+
+	var updates int
+	onUpdate := func(peer string, result FetchResult) { updates++ }
+
+	require.NoError(t, s.Sync(context.Background(), []string{"peer-1"}, onUpdate))
+	require.NoError(t, s.Sync(context.Background(), []string{"peer-1"}, onUpdate))
+	assert.Equal(t, 1, updates, "a 304 response should not trigger a cache mutation")
+}
+
+func TestDiscoveryCacheSyncerRecordsBytesSavedOn304(t *testing.T) {
+	peerproxymetrics.Register()
+	defer peerproxymetrics.Reset()
+
+	round := 0
+	fetch := func(ctx context.Context, peer string, prevETag string) (FetchResult, error) {
+		round++
+		if round == 1 {
+			return FetchResult{Body: []byte("v1-body"), ETag: "v1"}, nil
+		}
+		// A real 304 response never carries a body; the syncer must fall back to the
+		// size it cached from the prior, non-304 fetch instead of len(result.Body).
+		return FetchResult{NotModified: true}, nil
+	}
+
+	s := newDiscoveryCacheSyncer(fetch, DiscoverySyncOptions{Concurrency: 1})
+	onUpdate := func(peer string, result FetchResult) {}
+
+	require.NoError(t, s.Sync(context.Background(), []string{"peer-1"}, onUpdate))
+	require.NoError(t, s.Sync(context.Background(), []string{"peer-1"}, onUpdate))
+
+	wantMetrics := `
+		# HELP apiserver_peer_discovery_bytes_saved_total [ALPHA] Total response bytes saved across all peers by skipping re-fetch of discovery documents that answered with a 304 Not Modified.
+		# TYPE apiserver_peer_discovery_bytes_saved_total counter
+		apiserver_peer_discovery_bytes_saved_total 7
+	`
+	if err := testutil.GatherAndCompare(legacyregistry.DefaultGatherer, strings.NewReader(wantMetrics), "apiserver_peer_discovery_bytes_saved_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDiscoveryCacheSyncerPropagatesFetchError(t *testing.T) {
+	fetch := func(ctx context.Context, peer string, prevETag string) (FetchResult, error) {
+		if peer == "bad-peer" {
+			return FetchResult{}, fmt.Errorf("fetch failed for %s", peer)
+		}
+		return FetchResult{Body: []byte(peer), ETag: "v1"}, nil
+	}
+
+	s := newDiscoveryCacheSyncer(fetch, DiscoverySyncOptions{Concurrency: 2})
+	err := s.Sync(context.Background(), []string{"good-peer", "bad-peer"}, func(peer string, result FetchResult) {})
+	assert.Error(t, err)
+}
@@ -0,0 +1,636 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package peerproxy lets an apiserver forward requests for a GVR it doesn't itself serve
+// to a peer apiserver that does, discovered through apiserver identity Leases.
+package peerproxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	apidiscoveryv2 "k8s.io/api/apidiscovery/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	peerproxymetrics "k8s.io/apiserver/pkg/util/peerproxy/metrics"
+	coordinationv1informers "k8s.io/client-go/informers/coordination/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// peerDiscoverySyncInterval is how often RunPeerDiscoveryCacheSync refreshes the
+	// discovery cache from every known peer.
+	peerDiscoverySyncInterval = 10 * time.Second
+	// peerDiscoveryRefilterInterval is how often RunPeerDiscoveryRefilter recomputes
+	// which GVRs should be excluded from the filtered cache because this server now
+	// serves them locally.
+	peerDiscoveryRefilterInterval = time.Minute
+)
+
+// Reconciler resolves a peer apiserver's identity Lease to a reachable network endpoint,
+// and keeps this server's own identity Lease alive, the same way the aggregated
+// discovery/peer-proxy "UnknownVersionInteroperabilityProxy" feature resolves peers today.
+type Reconciler interface {
+	// UpdateLease creates or renews this server's own identity lease, advertising
+	// publicIP and ports.
+	UpdateLease(serverID string, publicIP string, ports []corev1.EndpointPort) error
+	// DeleteLease removes this server's own identity lease.
+	DeleteLease(serverID string) error
+	// Destroy releases any resources held by the reconciler.
+	Destroy()
+	// GetEndpoint resolves serverID's identity lease to a host:port a request can be
+	// proxied to.
+	GetEndpoint(serverID string) (string, error)
+	// RemoveLease removes a peer's identity lease, e.g. once it has been observed dead.
+	RemoveLease(serverID string) error
+	// StopReconciling stops this server's own lease from being renewed.
+	StopReconciling()
+}
+
+// PeerDiscoveryCacheEntry is a single peer's last-synced aggregated discovery
+// information.
+type PeerDiscoveryCacheEntry struct {
+	// GVRs is the set of GroupVersionResources the peer's discovery document
+	// advertised, for quick "does some peer serve this" lookups.
+	GVRs map[schema.GroupVersionResource]bool
+	// GroupDiscovery is the peer's discovery document, unmodified other than having
+	// been decoded, for building merged aggregated discovery responses.
+	GroupDiscovery []apidiscoveryv2.APIGroupDiscovery
+}
+
+// peerProxyHandler discovers peer apiservers through their apiserver identity Leases,
+// keeps a cache of each peer's served GVRs via periodic discovery syncing, and proxies
+// requests for a GVR this server doesn't serve locally to a peer that does.
+type peerProxyHandler struct {
+	localServerID string
+	labelSelector string
+
+	leaseInformer coordinationv1informers.LeaseInformer
+	reconciler    Reconciler
+
+	negotiatedSerializer runtime.NegotiatedSerializer
+	loopbackClientConfig *rest.Config
+	proxyClientConfig    *transport.Config
+	httpClient           *http.Client
+
+	gvExclusionManager *gvExclusionManager
+	discoverySyncer    *discoveryCacheSyncer
+	livenessOpts       PeerLivenessOptions
+	peerSelector       PeerSelector
+	peerEndpoints      peerEndpointCache
+	// peerSource, when set via SetPeerSource, replaces the label-selected Lease
+	// listing below as the source of peers to sync discovery from - e.g. to federate
+	// in peers from a MultiPeerSource that also covers sibling clusters.
+	peerSource PeerSource
+
+	breakersMu sync.Mutex
+	breakers   map[string]*peerCircuitBreaker
+}
+
+// NewPeerProxyHandler returns a peerProxyHandler that discovers peers matching
+// labelSelector among leaseInformer's apiserver identity Leases, resolving each one's
+// endpoint through reconciler and proxying to it with proxyClientConfig's TLS settings.
+// livenessOpts configures the grace factor/clock IsLeaseLive uses to judge a peer's
+// Lease stale; the zero value matches IsLeaseLive's own documented defaults.
+func NewPeerProxyHandler(
+	localServerID string,
+	labelSelector string,
+	leaseInformer coordinationv1informers.LeaseInformer,
+	reconciler Reconciler,
+	negotiatedSerializer runtime.NegotiatedSerializer,
+	loopbackClientConfig *rest.Config,
+	proxyClientConfig *transport.Config,
+	livenessOpts PeerLivenessOptions,
+) (*peerProxyHandler, error) {
+	if _, err := labels.Parse(labelSelector); err != nil {
+		return nil, fmt.Errorf("parsing peer label selector %q: %w", labelSelector, err)
+	}
+
+	roundTripper, err := transport.New(proxyClientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("building peer proxy transport: %w", err)
+	}
+
+	h := &peerProxyHandler{
+		localServerID:        localServerID,
+		labelSelector:        labelSelector,
+		leaseInformer:        leaseInformer,
+		reconciler:           reconciler,
+		negotiatedSerializer: negotiatedSerializer,
+		loopbackClientConfig: loopbackClientConfig,
+		proxyClientConfig:    proxyClientConfig,
+		httpClient:           &http.Client{Transport: roundTripper},
+		gvExclusionManager:   newGVExclusionManager(),
+		livenessOpts:         livenessOpts,
+		peerSelector:         &RandomSelector{},
+		breakers:             map[string]*peerCircuitBreaker{},
+	}
+	h.discoverySyncer = newDiscoveryCacheSyncer(h.fetchPeerDiscoveryDocument, DiscoverySyncOptions{})
+	return h, nil
+}
+
+// SetPeerSelector installs the policy used to pick which peer a request is proxied to
+// when more than one peer serves its GVR. It defaults to a RandomSelector.
+func (h *peerProxyHandler) SetPeerSelector(selector PeerSelector) {
+	h.peerSelector = selector
+}
+
+// SetPeerSource replaces the built-in label-selected Lease listing as the source of
+// peers that syncPeerDiscoveryCache fetches discovery documents from - for example, a
+// MultiPeerSource that federates in peers from a sibling cluster whose Leases this
+// server's own informer never sees.
+func (h *peerProxyHandler) SetPeerSource(source PeerSource) {
+	h.peerSource = source
+}
+
+// circuitBreakerForPeer returns peer's circuit breaker, creating one in the closed state
+// the first time peer is seen.
+func (h *peerProxyHandler) circuitBreakerForPeer(peer string) *peerCircuitBreaker {
+	h.breakersMu.Lock()
+	defer h.breakersMu.Unlock()
+	cb, ok := h.breakers[peer]
+	if !ok {
+		cb = newPeerCircuitBreaker(peer, CircuitBreakerOptions{})
+		h.breakers[peer] = cb
+	}
+	return cb
+}
+
+// RunPeerDiscoveryCacheSync periodically syncs the discovery cache from every known
+// peer until ctx is done, fetching from up to workers peers concurrently per round. It
+// syncs once immediately on entry rather than waiting out the first interval.
+func (h *peerProxyHandler) RunPeerDiscoveryCacheSync(ctx context.Context, workers int) {
+	if workers > 0 {
+		h.discoverySyncer.opts.Concurrency = workers
+	}
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := h.syncPeerDiscoveryCache(ctx); err != nil {
+			klog.FromContext(ctx).V(4).Info("peer discovery cache sync round did not fully complete", "err", err)
+		}
+	}, peerDiscoverySyncInterval)
+}
+
+// RunPeerDiscoveryRefilter periodically recomputes which GVRs in the discovery cache
+// are also served by this apiserver locally, so ServeHTTP never proxies a request this
+// server can already answer itself.
+func (h *peerProxyHandler) RunPeerDiscoveryRefilter(ctx context.Context) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		excluded, err := h.localGVRs()
+		if err != nil {
+			klog.FromContext(ctx).V(4).Info("failed to refresh locally served GVRs for peer discovery exclusion", "err", err)
+			return
+		}
+		h.gvExclusionManager.SetExcludedGVRs(excluded)
+	}, peerDiscoveryRefilterInterval)
+}
+
+// localGVRs returns the set of GVRs this apiserver serves locally, by asking its own
+// loopback client for its discovery document.
+func (h *peerProxyHandler) localGVRs() (map[schema.GroupVersionResource]bool, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(h.loopbackClientConfig)
+	if err != nil {
+		return nil, err
+	}
+	_, resourceLists, err := discoveryClient.ServerGroupsAndResources()
+	if err != nil {
+		return nil, err
+	}
+
+	gvrs := map[schema.GroupVersionResource]bool{}
+	for _, rl := range resourceLists {
+		gv, err := schema.ParseGroupVersion(rl.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range rl.APIResources {
+			gvrs[gv.WithResource(r.Name)] = true
+		}
+	}
+	return gvrs, nil
+}
+
+// syncPeerDiscoveryCache lists every peer identity lease matching h.labelSelector,
+// evicts peers whose lease disappeared or whose liveness TTL expired, and fetches fresh
+// discovery documents from the rest, bounding concurrency and coalescing duplicate
+// fetches through h.discoverySyncer.
+func (h *peerProxyHandler) syncPeerDiscoveryCache(ctx context.Context) error {
+	if h.peerSource != nil {
+		return h.syncPeerDiscoveryCacheFromSource(ctx)
+	}
+
+	selector, err := labels.Parse(h.labelSelector)
+	if err != nil {
+		return fmt.Errorf("parsing peer label selector: %w", err)
+	}
+
+	leases, err := h.leaseInformer.Lister().List(selector)
+	if err != nil {
+		peerproxymetrics.IncPeerDiscoverySyncError(ctx, peerproxymetrics.DiscoveryErrorLeaseList)
+		return fmt.Errorf("listing peer identity leases: %w", err)
+	}
+
+	liveLeases := map[string]bool{}
+	var peers []string
+	endpoints := map[string]string{}
+	for _, lease := range leases {
+		if lease.Name == h.localServerID {
+			continue
+		}
+		liveLeases[lease.Name] = true
+
+		if !IsLeaseLive(lease, h.livenessOpts) {
+			h.gvExclusionManager.DeleteEntry(lease.Name)
+			peerproxymetrics.IncPeerDiscoveryStaleLeaseEviction(ctx)
+			continue
+		}
+
+		if allow, _ := h.circuitBreakerForPeer(lease.Name).Allow(); !allow {
+			continue
+		}
+
+		endpoint, err := h.reconciler.GetEndpoint(lease.Name)
+		if err != nil {
+			peerproxymetrics.IncPeerDiscoverySyncError(ctx, peerproxymetrics.DiscoveryErrorHostPortResolution)
+			continue
+		}
+
+		endpoints[lease.Name] = endpoint
+		peers = append(peers, lease.Name)
+	}
+
+	h.gvExclusionManager.PruneExcept(liveLeases)
+	peerproxymetrics.SetPeerDiscoveryKnownPeers(len(peers))
+	h.peerEndpoints.set(endpoints)
+
+	return h.discoverySyncer.Sync(ctx, peers, func(peer string, result FetchResult) {
+		var list apidiscoveryv2.APIGroupDiscoveryList
+		if err := json.Unmarshal(result.Body, &list); err != nil {
+			return
+		}
+		h.gvExclusionManager.SetEntry(peer, discoveryEntryFromGroups(list.Items))
+	})
+}
+
+// syncPeerDiscoveryCacheFromSource is syncPeerDiscoveryCache's counterpart once
+// SetPeerSource has replaced the built-in label-selected Lease listing: h.peerSource
+// already resolves each peer to an endpoint, so there is no separate hostport
+// resolution step or liveness-by-lease-TTL check to make here.
+func (h *peerProxyHandler) syncPeerDiscoveryCacheFromSource(ctx context.Context) error {
+	refs, err := h.peerSource.List(ctx)
+	if err != nil {
+		peerproxymetrics.IncPeerDiscoverySyncError(ctx, peerproxymetrics.DiscoveryErrorLeaseList)
+		return fmt.Errorf("listing peers: %w", err)
+	}
+
+	liveIDs := map[string]bool{}
+	peers := make([]string, 0, len(refs))
+	endpoints := make(map[string]string, len(refs))
+	for _, ref := range refs {
+		if ref.ID == h.localServerID {
+			continue
+		}
+		liveIDs[ref.ID] = true
+		if allow, _ := h.circuitBreakerForPeer(ref.ID).Allow(); !allow {
+			continue
+		}
+		endpoints[ref.ID] = ref.Endpoint
+		peers = append(peers, ref.ID)
+	}
+
+	h.gvExclusionManager.PruneExcept(liveIDs)
+	peerproxymetrics.SetPeerDiscoveryKnownPeers(len(peers))
+	h.peerEndpoints.set(endpoints)
+
+	return h.discoverySyncer.Sync(ctx, peers, func(peer string, result FetchResult) {
+		var list apidiscoveryv2.APIGroupDiscoveryList
+		if err := json.Unmarshal(result.Body, &list); err != nil {
+			return
+		}
+		h.gvExclusionManager.SetEntry(peer, discoveryEntryFromGroups(list.Items))
+	})
+}
+
+// fetchPeerDiscoveryDocument fetches peer's aggregated discovery document for
+// discoveryCacheSyncer, trying the aggregated "/apis" endpoint first and falling back to
+// the legacy "/api" endpoint - which is all an older peer apiserver might support -
+// recording a fetch-discovery error metric for each failed attempt.
+func (h *peerProxyHandler) fetchPeerDiscoveryDocument(ctx context.Context, peer, prevETag string) (FetchResult, error) {
+	cb := h.circuitBreakerForPeer(peer)
+	host := peer
+	if endpoint, ok := h.peerEndpoints.get(peer); ok {
+		host = endpoint
+	}
+
+	var lastErr error
+	for _, path := range []string{"/apis", "/api"} {
+		result, err := h.fetchPeerDiscoveryPath(ctx, host, path, prevETag)
+		if err == nil {
+			cb.RecordSuccess()
+			return result, nil
+		}
+		peerproxymetrics.IncPeerDiscoverySyncError(ctx, peerproxymetrics.DiscoveryErrorFetch)
+		lastErr = err
+	}
+	cb.RecordFailure()
+	return FetchResult{}, lastErr
+}
+
+// fetchPeerDiscoveryPath fetches a single discovery path from peer.
+func (h *peerProxyHandler) fetchPeerDiscoveryPath(ctx context.Context, peer, path, prevETag string) (FetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+peer+path, nil)
+	if err != nil {
+		return FetchResult{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if prevETag != "" {
+		req.Header.Set("If-None-Match", prevETag)
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return FetchResult{}, fmt.Errorf("fetching discovery from peer %s%s: %w", peer, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return FetchResult{ETag: prevETag, NotModified: true}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return FetchResult{}, fmt.Errorf("peer %s responded to %s with status %d", peer, path, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return FetchResult{}, fmt.Errorf("reading discovery response from peer %s%s: %w", peer, path, err)
+	}
+	return FetchResult{Body: body, ETag: resp.Header.Get("ETag")}, nil
+}
+
+// discoveryEntryFromGroups builds a PeerDiscoveryCacheEntry out of a decoded aggregated
+// discovery document.
+func discoveryEntryFromGroups(groups []apidiscoveryv2.APIGroupDiscovery) PeerDiscoveryCacheEntry {
+	entry := PeerDiscoveryCacheEntry{
+		GVRs:           map[schema.GroupVersionResource]bool{},
+		GroupDiscovery: groups,
+	}
+	for _, g := range groups {
+		for _, v := range g.Versions {
+			for _, r := range v.Resources {
+				entry.GVRs[schema.GroupVersionResource{Group: g.Name, Version: v.Version, Resource: r.Resource}] = true
+			}
+		}
+	}
+	return entry
+}
+
+// WrapHandler returns an http.Handler that proxies a request to a peer apiserver known
+// to serve its GVR, falling back to localHandler whenever no peer is known to serve it,
+// the peer picked by h.peerSelector has an open circuit breaker, or that peer's endpoint
+// is unknown. A failed round-trip to the picked peer is answered with a 502 and is not
+// retried locally or on another peer.
+func (h *peerProxyHandler) WrapHandler(localHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info, ok := genericapirequest.RequestInfoFrom(r.Context())
+		if !ok || !info.IsResourceRequest {
+			localHandler.ServeHTTP(w, r)
+			return
+		}
+		gvr := schema.GroupVersionResource{Group: info.APIGroup, Version: info.APIVersion, Resource: info.Resource}
+
+		peers := h.peersServing(gvr)
+		if len(peers) == 0 {
+			localHandler.ServeHTTP(w, r)
+			return
+		}
+
+		peer, err := h.peerSelector.Pick(gvr, peers)
+		if err != nil {
+			localHandler.ServeHTTP(w, r)
+			return
+		}
+
+		cb := h.circuitBreakerForPeer(peer)
+		allow, retryAfter := cb.Allow()
+		if !allow {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			peerproxymetrics.IncPeerProxiedRequestByPeer(r.Context(), peer, peerproxymetrics.OutcomeFallback, info.APIGroup, info.APIVersion, info.Resource)
+			localHandler.ServeHTTP(w, r)
+			return
+		}
+
+		endpoint, ok := h.peerEndpoints.get(peer)
+		if !ok {
+			cb.RecordFailure()
+			peerproxymetrics.IncPeerProxyError(r.Context(), peerproxymetrics.ProxyErrorEndpointResolution, info.APIGroup, info.APIVersion, info.Resource)
+			localHandler.ServeHTTP(w, r)
+			return
+		}
+
+		if tracker, ok := h.peerSelector.(loadTrackingSelector); ok {
+			tracker.StartRequest(peer)
+			defer tracker.FinishRequest(peer)
+		}
+
+		start := time.Now()
+		proxyErr := h.proxyToPeer(w, r, endpoint)
+		duration := time.Since(start)
+		if proxyErr != nil {
+			// The reverse proxy has already written a 502 to w by the time its
+			// ErrorHandler runs, so unlike the other failure paths above, there's no
+			// falling back to localHandler here - the response is already committed.
+			cb.RecordFailure()
+			peerproxymetrics.IncPeerProxyError(r.Context(), peerproxymetrics.ProxyErrorRoundTrip, info.APIGroup, info.APIVersion, info.Resource)
+			peerproxymetrics.IncPeerProxiedRequestByPeer(r.Context(), peer, peerproxymetrics.OutcomeGiveUp, info.APIGroup, info.APIVersion, info.Resource)
+			return
+		}
+		cb.RecordSuccess()
+		if tracker, ok := h.peerSelector.(latencyTrackingSelector); ok {
+			tracker.RecordLatency(peer, duration)
+		}
+		peerproxymetrics.ObservePeerProxyRequestDuration(r.Context(), peer, info.APIGroup, info.APIVersion, info.Resource, duration)
+		peerproxymetrics.IncPeerProxiedRequestByPeer(r.Context(), peer, peerproxymetrics.OutcomeProxied, info.APIGroup, info.APIVersion, info.Resource)
+	})
+}
+
+// peersServing returns the peers whose last-synced, locally-filtered discovery cache
+// entry advertises gvr.
+func (h *peerProxyHandler) peersServing(gvr schema.GroupVersionResource) []string {
+	var peers []string
+	for peer, entry := range h.gvExclusionManager.GetFilteredPeerDiscoveryCache() {
+		if entry.GVRs[gvr] {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+// proxyToPeer forwards r to endpoint over h.httpClient's transport, and returns the
+// round-trip error if the proxy attempt failed. httputil.ReverseProxy's default
+// ErrorHandler swallows that error into a client-visible 502 and never surfaces it to
+// the caller, which would otherwise leave WrapHandler unable to tell a good proxy from
+// a dead peer and record it against endpoint's circuit breaker.
+func (h *peerProxyHandler) proxyToPeer(w http.ResponseWriter, r *http.Request, endpoint string) error {
+	proxy := httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "https", Host: endpoint})
+	proxy.Transport = h.httpClient.Transport
+
+	var proxyErr error
+	proxy.ErrorHandler = func(rw http.ResponseWriter, _ *http.Request, err error) {
+		proxyErr = err
+		rw.WriteHeader(http.StatusBadGateway)
+	}
+	proxy.ServeHTTP(w, r)
+	return proxyErr
+}
+
+// gvExclusionManager tracks each peer's last-synced discovery entry (raw) alongside a
+// filtered view with GVRs this server serves locally removed, so ServeHTTP never
+// proxies a request this server can already answer itself.
+type gvExclusionManager struct {
+	mu sync.RWMutex
+
+	raw          map[string]PeerDiscoveryCacheEntry
+	filtered     map[string]PeerDiscoveryCacheEntry
+	excludedGVRs map[schema.GroupVersionResource]bool
+}
+
+func newGVExclusionManager() *gvExclusionManager {
+	return &gvExclusionManager{
+		raw:          map[string]PeerDiscoveryCacheEntry{},
+		filtered:     map[string]PeerDiscoveryCacheEntry{},
+		excludedGVRs: map[schema.GroupVersionResource]bool{},
+	}
+}
+
+// SetEntry records peer's freshly synced discovery entry.
+func (m *gvExclusionManager) SetEntry(peer string, entry PeerDiscoveryCacheEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.raw[peer] = entry
+	m.filtered[peer] = m.filterLocked(entry)
+}
+
+// DeleteEntry removes peer's cached discovery entry entirely.
+func (m *gvExclusionManager) DeleteEntry(peer string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.raw, peer)
+	delete(m.filtered, peer)
+}
+
+// PruneExcept removes every cached peer not present in keep, for peers whose identity
+// lease disappeared (deleted, or no longer matching the label selector) between syncs.
+func (m *gvExclusionManager) PruneExcept(keep map[string]bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for peer := range m.raw {
+		if !keep[peer] {
+			delete(m.raw, peer)
+			delete(m.filtered, peer)
+		}
+	}
+}
+
+// SetExcludedGVRs replaces the set of GVRs considered locally served, and recomputes
+// every cached peer's filtered entry against it.
+func (m *gvExclusionManager) SetExcludedGVRs(excluded map[schema.GroupVersionResource]bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.excludedGVRs = excluded
+	for peer, entry := range m.raw {
+		m.filtered[peer] = m.filterLocked(entry)
+	}
+}
+
+func (m *gvExclusionManager) filterLocked(entry PeerDiscoveryCacheEntry) PeerDiscoveryCacheEntry {
+	if len(m.excludedGVRs) == 0 {
+		return entry
+	}
+
+	filtered := PeerDiscoveryCacheEntry{GVRs: map[schema.GroupVersionResource]bool{}}
+	for gvr := range entry.GVRs {
+		if !m.excludedGVRs[gvr] {
+			filtered.GVRs[gvr] = true
+		}
+	}
+	for _, group := range entry.GroupDiscovery {
+		var versions []apidiscoveryv2.APIVersionDiscovery
+		for _, v := range group.Versions {
+			var resources []apidiscoveryv2.APIResourceDiscovery
+			for _, r := range v.Resources {
+				if !m.excludedGVRs[(schema.GroupVersionResource{Group: group.Name, Version: v.Version, Resource: r.Resource})] {
+					resources = append(resources, r)
+				}
+			}
+			if len(resources) > 0 {
+				v.Resources = resources
+				versions = append(versions, v)
+			}
+		}
+		if len(versions) > 0 {
+			group.Versions = versions
+			filtered.GroupDiscovery = append(filtered.GroupDiscovery, group)
+		}
+	}
+	return filtered
+}
+
+// GetFilteredPeerDiscoveryCache returns a snapshot of every peer's filtered discovery
+// entry.
+func (m *gvExclusionManager) GetFilteredPeerDiscoveryCache() map[string]PeerDiscoveryCacheEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string]PeerDiscoveryCacheEntry, len(m.filtered))
+	for k, v := range m.filtered {
+		out[k] = v
+	}
+	return out
+}
+
+// peerEndpointCache is a small concurrency-safe map from peer ID to its last-resolved
+// host:port, refreshed once per syncPeerDiscoveryCache round so WrapHandler never has to
+// call the Reconciler on the request path.
+type peerEndpointCache struct {
+	mu        sync.RWMutex
+	endpoints map[string]string
+}
+
+func (c *peerEndpointCache) set(endpoints map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.endpoints = endpoints
+}
+
+func (c *peerEndpointCache) get(peer string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	endpoint, ok := c.endpoints[peer]
+	return endpoint, ok
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peerproxy
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// newTestWrapHandlerFixture returns a peerProxyHandler with just enough state for
+// WrapHandler to run against a single peer "peer-1" serving gvr, without the discovery
+// sync/Lease machinery the rest of the package's tests exercise.
+func newTestWrapHandlerFixture(t *testing.T, gvr schema.GroupVersionResource, peerEndpoint string) *peerProxyHandler {
+	t.Helper()
+	h := &peerProxyHandler{
+		gvExclusionManager: newGVExclusionManager(),
+		peerSelector:       &RandomSelector{},
+		breakers:           map[string]*peerCircuitBreaker{},
+		httpClient: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		},
+	}
+	h.gvExclusionManager.SetEntry("peer-1", PeerDiscoveryCacheEntry{GVRs: map[schema.GroupVersionResource]bool{gvr: true}})
+	h.peerEndpoints.set(map[string]string{"peer-1": peerEndpoint})
+	return h
+}
+
+func resourceRequest(gvr schema.GroupVersionResource) *http.Request {
+	info := &genericapirequest.RequestInfo{
+		IsResourceRequest: true,
+		APIGroup:          gvr.Group,
+		APIVersion:        gvr.Version,
+		Resource:          gvr.Resource,
+	}
+	req := httptest.NewRequest(http.MethodGet, "/apis/"+gvr.Group+"/"+gvr.Version+"/"+gvr.Resource, nil)
+	return req.WithContext(genericapirequest.WithRequestInfo(req.Context(), info))
+}
+
+func TestWrapHandlerOpensCircuitBreakerAfterRepeatedProxyFailures(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "testgroup", Version: "v1", Resource: "testresources"}
+
+	// Nothing is listening on this endpoint, so every proxy attempt fails its
+	// round-trip and proxyToPeer must report that failure back to WrapHandler.
+	closedServer := httptest.NewUnstartedServer(nil)
+	deadEndpoint := closedServer.Listener.Addr().String()
+	closedServer.Listener.Close()
+
+	h := newTestWrapHandlerFixture(t, gvr, deadEndpoint)
+
+	var localCalls int
+	var mu sync.Mutex
+	localHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		localCalls++
+		mu.Unlock()
+	})
+	wrapped := h.WrapHandler(localHandler)
+
+	// CircuitBreakerOptions{} (circuitBreakerForPeer's default) opens after 5
+	// consecutive failures; each of these round-trips should fail and record a
+	// failure, not fall back to localHandler, since the reverse proxy has already
+	// committed a response by the time WrapHandler learns about the error.
+	for i := 0; i < 5; i++ {
+		w := httptest.NewRecorder()
+		wrapped.ServeHTTP(w, resourceRequest(gvr))
+		assert.Equal(t, http.StatusBadGateway, w.Code, "attempt %d should surface the failed round-trip as a 502, not silently succeed", i)
+	}
+	assert.Equal(t, 0, localCalls, "a failing peer round-trip must not also invoke localHandler: the response is already committed")
+
+	cb := h.circuitBreakerForPeer("peer-1")
+	assert.Equal(t, CircuitOpen, cb.State(), "5 consecutive round-trip failures should have opened the breaker")
+
+	// Now that the breaker is open, the next request should fall back to
+	// localHandler instead of attempting another doomed proxy call.
+	w := httptest.NewRecorder()
+	wrapped.ServeHTTP(w, resourceRequest(gvr))
+	assert.Equal(t, 1, localCalls, "an open breaker should fall back to localHandler")
+}
+
+func TestWrapHandlerRecordsSuccessOnGoodProxy(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "testgroup", Version: "v1", Resource: "testresources"}
+
+	peer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer peer.Close()
+
+	h := newTestWrapHandlerFixture(t, gvr, peer.Listener.Addr().String())
+
+	localHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("localHandler should not be invoked when the peer proxies successfully")
+	})
+	wrapped := h.WrapHandler(localHandler)
+
+	w := httptest.NewRecorder()
+	wrapped.ServeHTTP(w, resourceRequest(gvr))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	cb := h.circuitBreakerForPeer("peer-1")
+	assert.Equal(t, CircuitClosed, cb.State(), "a successful proxy must not trip the breaker")
+}
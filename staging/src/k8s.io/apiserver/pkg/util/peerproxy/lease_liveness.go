@@ -0,0 +1,69 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peerproxy
+
+import (
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+)
+
+// PeerLivenessOptions configures how liveness-by-lease-TTL is judged for a peer
+// apiserver's identity Lease, mirroring how client-go's leaderelection package treats a
+// lease's RenewTime+LeaseDurationSeconds as the authoritative liveness signal rather
+// than waiting for the lease object itself to be garbage-collected or deleted.
+// syncPeerDiscoveryCache evicts a peer whose IsLeaseLive now reports false from the
+// discovery cache and records peerproxymetrics.IncPeerDiscoveryStaleLeaseEviction.
+type PeerLivenessOptions struct {
+	// GraceFactor multiplies LeaseDurationSeconds before comparing it against the time
+	// elapsed since RenewTime, so a lease isn't treated as expired the instant its
+	// nominal TTL passes if clock skew between peers is expected. A value <= 0 uses 1.0
+	// (no extra grace).
+	GraceFactor float64
+	// Clock returns the current time; defaults to time.Now if nil. Exists so tests can
+	// inject a fake clock instead of depending on real wall-clock time.
+	Clock func() time.Time
+}
+
+func (o PeerLivenessOptions) graceFactor() float64 {
+	if o.GraceFactor <= 0 {
+		return 1.0
+	}
+	return o.GraceFactor
+}
+
+func (o PeerLivenessOptions) now() time.Time {
+	if o.Clock != nil {
+		return o.Clock()
+	}
+	return time.Now()
+}
+
+// IsLeaseLive reports whether lease's RenewTime, plus its LeaseDurationSeconds scaled
+// by opts.GraceFactor, is still in the future - i.e. whether the peer that holds it
+// should be considered live rather than evicted immediately instead of waiting for an
+// informer DELETE event. A lease missing RenewTime or LeaseDurationSeconds is treated
+// as live, since both fields are optional in the Lease API and their absence isn't
+// evidence of a dead peer.
+func IsLeaseLive(lease *coordinationv1.Lease, opts PeerLivenessOptions) bool {
+	if lease == nil || lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	ttl := time.Duration(float64(*lease.Spec.LeaseDurationSeconds) * float64(time.Second) * opts.graceFactor())
+	expiry := lease.Spec.RenewTime.Time.Add(ttl)
+	return opts.now().Before(expiry)
+}
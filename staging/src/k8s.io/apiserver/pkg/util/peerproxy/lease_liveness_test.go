@@ -0,0 +1,86 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peerproxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsLeaseLive(t *testing.T) {
+	fixedNow := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	fakeClock := func() time.Time { return fixedNow }
+
+	leaseWithAge := func(age time.Duration, durationSeconds int32) *coordinationv1.Lease {
+		renewTime := metav1.NewMicroTime(fixedNow.Add(-age))
+		return &coordinationv1.Lease{
+			Spec: coordinationv1.LeaseSpec{
+				RenewTime:            &renewTime,
+				LeaseDurationSeconds: &durationSeconds,
+			},
+		}
+	}
+
+	testCases := []struct {
+		desc  string
+		lease *coordinationv1.Lease
+		opts  PeerLivenessOptions
+		want  bool
+	}{
+		{
+			desc:  "nil lease is treated as live",
+			lease: nil,
+			opts:  PeerLivenessOptions{Clock: fakeClock},
+			want:  true,
+		},
+		{
+			desc:  "lease missing RenewTime is treated as live",
+			lease: &coordinationv1.Lease{},
+			opts:  PeerLivenessOptions{Clock: fakeClock},
+			want:  true,
+		},
+		{
+			desc:  "fresh lease is live",
+			lease: leaseWithAge(5*time.Second, 30),
+			opts:  PeerLivenessOptions{Clock: fakeClock},
+			want:  true,
+		},
+		{
+			desc:  "lease past its TTL is not live",
+			lease: leaseWithAge(60*time.Second, 30),
+			opts:  PeerLivenessOptions{Clock: fakeClock},
+			want:  false,
+		},
+		{
+			desc:  "lease past its nominal TTL but within the grace factor is still live",
+			lease: leaseWithAge(40*time.Second, 30),
+			opts:  PeerLivenessOptions{Clock: fakeClock, GraceFactor: 2},
+			want:  true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsLeaseLive(tt.lease, tt.opts))
+		})
+	}
+}
@@ -19,6 +19,7 @@ package metrics
 import (
 	"context"
 	"sync"
+	"time"
 
 	"k8s.io/component-base/metrics"
 	"k8s.io/component-base/metrics/legacyregistry"
@@ -31,11 +32,18 @@ const (
 	version    = "version"
 	resource   = "resource"
 	errorType  = "type"
+	peer       = "peer"
+	outcome    = "outcome"
+	state      = "state"
 
 	// ProxyErrorEndpointResolution indicates a failure to resolve the network address of a peer apiserver.
 	ProxyErrorEndpointResolution = "endpoint_resolution"
 	// ProxyErrorTransport indicates a failure to build the proxy transport for the request.
 	ProxyErrorTransport = "proxy_transport"
+	// ProxyErrorRoundTrip indicates the proxied request's round-trip to the peer itself
+	// failed (e.g. dial failure, TLS handshake failure, or the peer closing the
+	// connection), as opposed to a failure to set the proxy up in the first place.
+	ProxyErrorRoundTrip = "round_trip"
 
 	// DiscoveryErrorLeaseList indicates a failure to list apiserver identity leases.
 	DiscoveryErrorLeaseList = "lease_list"
@@ -43,6 +51,13 @@ const (
 	DiscoveryErrorHostPortResolution = "hostport_resolution"
 	// DiscoveryErrorFetch indicates a failure to fetch discovery document from a peer.
 	DiscoveryErrorFetch = "fetch_discovery"
+
+	// OutcomeProxied indicates the request was successfully proxied to a peer apiserver.
+	OutcomeProxied = "proxied"
+	// OutcomeFallback indicates the request fell back to being served locally after a failed proxy attempt.
+	OutcomeFallback = "fallback"
+	// OutcomeGiveUp indicates the proxy gave up on the request without serving it locally or remotely.
+	OutcomeGiveUp = "giveup"
 )
 
 var registerMetricsOnce sync.Once
@@ -80,6 +95,103 @@ var (
 		},
 		[]string{errorType},
 	)
+
+	// peerProxiedRequestsByPeerTotal counts the number of requests proxied to a peer kube-apiserver, broken down
+	// by the target peer and the outcome of the proxy attempt.
+	peerProxiedRequestsByPeerTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      subsystem,
+			Name:           "peer_proxy_requests_by_peer_total",
+			Help:           "Total number of requests proxied to a peer kube-apiserver, broken down by the target peer and outcome (proxied, fallback, giveup)",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{peer, outcome, group, version, resource},
+	)
+
+	// peerProxyRequestDurationSeconds measures the round-trip latency of requests proxied to a peer kube-apiserver.
+	peerProxyRequestDurationSeconds = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      subsystem,
+			Name:           "peer_proxy_request_duration_seconds",
+			Help:           "Round-trip latency in seconds of requests proxied to a peer kube-apiserver, broken down by the target peer.",
+			Buckets:        metrics.ExponentialBuckets(0.001, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{peer, group, version, resource},
+	)
+
+	// peerDiscoveryKnownPeers is a gauge of the number of peer apiservers currently known to the discovery sync loop.
+	peerDiscoveryKnownPeers = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Subsystem:      subsystem,
+			Name:           "peer_discovery_known_peers",
+			Help:           "Number of peer apiservers currently known to the peer discovery cache sync loop.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	// peerDiscoveryStaleLeaseEvictionsTotal counts the number of peers evicted from the discovery cache
+	// because their identity lease's RenewTime/LeaseDurationSeconds showed it expired, rather than
+	// because an informer DELETE event removed the lease.
+	peerDiscoveryStaleLeaseEvictionsTotal = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Subsystem:      subsystem,
+			Name:           "peer_discovery_stale_lease_evictions_total",
+			Help:           "Total number of peers evicted from the peer discovery cache because their identity lease's TTL expired.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	// peerProxyCircuitState is a gauge of 1 for the current state of each peer's circuit breaker
+	// (closed, open, half-open) and 0 for its other states, so a query for a specific state can
+	// select on the "state" label.
+	peerProxyCircuitState = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      subsystem,
+			Name:           "peer_proxy_circuit_state",
+			Help:           "State (1 = current, 0 = not current) of each peer's circuit breaker, broken down by peer and state (closed, open, half-open).",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{peer, state},
+	)
+
+	// peerProxyCircuitTransitionsTotal counts the number of times a peer's circuit breaker
+	// transitioned into a new state.
+	peerProxyCircuitTransitionsTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      subsystem,
+			Name:           "peer_proxy_circuit_transitions_total",
+			Help:           "Total number of times a peer's circuit breaker transitioned into a new state, broken down by peer and the state it transitioned to.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{peer, state},
+	)
+
+	// peerDiscoverySyncDurationSeconds measures how long a full peer discovery cache sync
+	// round took to fetch from every peer, broken down by whether the round as a whole
+	// succeeded or encountered at least one peer error.
+	peerDiscoverySyncDurationSeconds = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      subsystem,
+			Name:           "peer_discovery_sync_duration_seconds",
+			Help:           "Duration in seconds of a peer discovery cache sync round, broken down by outcome (success, error).",
+			Buckets:        metrics.ExponentialBuckets(0.001, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{outcome},
+	)
+
+	// peerDiscoveryBytesSavedTotal counts the response bytes a discovery sync round did not
+	// have to re-fetch because the peer answered with a 304 Not Modified for a previously
+	// cached discovery document.
+	peerDiscoveryBytesSavedTotal = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Subsystem:      subsystem,
+			Name:           "peer_discovery_bytes_saved_total",
+			Help:           "Total response bytes saved across all peers by skipping re-fetch of discovery documents that answered with a 304 Not Modified.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
 )
 
 func Register() {
@@ -87,6 +199,14 @@ func Register() {
 		legacyregistry.MustRegister(peerProxiedRequestsTotal)
 		legacyregistry.MustRegister(peerProxyErrorsTotal)
 		legacyregistry.MustRegister(peerDiscoverySyncErrorsTotal)
+		legacyregistry.MustRegister(peerProxiedRequestsByPeerTotal)
+		legacyregistry.MustRegister(peerProxyRequestDurationSeconds)
+		legacyregistry.MustRegister(peerDiscoveryKnownPeers)
+		legacyregistry.MustRegister(peerDiscoveryStaleLeaseEvictionsTotal)
+		legacyregistry.MustRegister(peerProxyCircuitState)
+		legacyregistry.MustRegister(peerProxyCircuitTransitionsTotal)
+		legacyregistry.MustRegister(peerDiscoverySyncDurationSeconds)
+		legacyregistry.MustRegister(peerDiscoveryBytesSavedTotal)
 	})
 }
 
@@ -109,3 +229,55 @@ func IncPeerProxyError(ctx context.Context, e, g, v, r string) {
 func IncPeerDiscoverySyncError(ctx context.Context, e string) {
 	peerDiscoverySyncErrorsTotal.WithContext(ctx).WithLabelValues(e).Add(1)
 }
+
+// IncPeerProxiedRequestByPeer increments the # of proxied requests broken down by the target peer and outcome
+func IncPeerProxiedRequestByPeer(ctx context.Context, peerID, outcome, g, v, r string) {
+	peerProxiedRequestsByPeerTotal.WithContext(ctx).WithLabelValues(peerID, outcome, g, v, r).Add(1)
+}
+
+// ObservePeerProxyRequestDuration records the round-trip latency of a request proxied to a peer kube-apiserver
+func ObservePeerProxyRequestDuration(ctx context.Context, peerID, g, v, r string, duration time.Duration) {
+	peerProxyRequestDurationSeconds.WithContext(ctx).WithLabelValues(peerID, g, v, r).Observe(duration.Seconds())
+}
+
+// SetPeerDiscoveryKnownPeers sets the gauge of peer apiservers currently known to the discovery sync loop
+func SetPeerDiscoveryKnownPeers(count int) {
+	peerDiscoveryKnownPeers.Set(float64(count))
+}
+
+// IncPeerDiscoveryStaleLeaseEviction increments the # of peers evicted from the discovery cache because
+// their identity lease's TTL expired, rather than because an informer DELETE event removed it.
+func IncPeerDiscoveryStaleLeaseEviction(ctx context.Context) {
+	peerDiscoveryStaleLeaseEvictionsTotal.WithContext(ctx).Add(1)
+}
+
+// SetPeerProxyCircuitState records peerID's circuit breaker as currently being in
+// currentState: it sets currentState's gauge to 1 and every other known state's gauge
+// to 0, so a point-in-time query for a given state returns exactly the peers in it.
+func SetPeerProxyCircuitState(peerID, currentState string, allStates []string) {
+	for _, s := range allStates {
+		value := 0.0
+		if s == currentState {
+			value = 1.0
+		}
+		peerProxyCircuitState.WithLabelValues(peerID, s).Set(value)
+	}
+}
+
+// IncPeerProxyCircuitTransition increments the # of times peerID's circuit breaker
+// transitioned into newState.
+func IncPeerProxyCircuitTransition(ctx context.Context, peerID, newState string) {
+	peerProxyCircuitTransitionsTotal.WithContext(ctx).WithLabelValues(peerID, newState).Add(1)
+}
+
+// ObservePeerDiscoverySyncDuration records how long a full discovery sync round took,
+// broken down by whether it completed without error.
+func ObservePeerDiscoverySyncDuration(ctx context.Context, outcome string, duration time.Duration) {
+	peerDiscoverySyncDurationSeconds.WithContext(ctx).WithLabelValues(outcome).Observe(duration.Seconds())
+}
+
+// AddPeerDiscoveryBytesSaved adds n response bytes to the total saved by skipping
+// re-fetch of discovery documents that answered with a 304 Not Modified.
+func AddPeerDiscoveryBytesSaved(n int) {
+	peerDiscoveryBytesSavedTotal.Add(float64(n))
+}
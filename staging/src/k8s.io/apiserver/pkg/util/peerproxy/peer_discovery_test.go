@@ -346,6 +346,7 @@ func setupPeerProxyHandler(t *testing.T, labelSelector string) (*peerProxyHandle
 		negotiatedSerializer,
 		loopbackConfig,
 		proxyConfig,
+		PeerLivenessOptions{},
 	)
 	if err != nil {
 		t.Fatalf("failed to create handler: %v", err)
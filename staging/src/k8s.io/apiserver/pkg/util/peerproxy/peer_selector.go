@@ -0,0 +1,198 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peerproxy
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// PeerSelector picks which of peers - all of which are known to serve gvr - a single
+// request should be proxied to. peerProxyHandler defaults to a RandomSelector; call its
+// SetPeerSelector to install a RoundRobinSelector, LeastLoadedSelector, LatencySelector,
+// or a custom policy instead.
+type PeerSelector interface {
+	// Pick returns the peer to proxy a request for gvr to, out of peers. peers is
+	// never empty.
+	Pick(gvr schema.GroupVersionResource, peers []string) (string, error)
+}
+
+// loadTrackingSelector is implemented by PeerSelectors (LeastLoadedSelector) that need
+// to know when a proxy attempt starts and finishes to keep their in-flight counts
+// accurate. WrapHandler type-asserts h.peerSelector against this so it can drive the
+// calls around the real proxy round-trip instead of leaving them test-only.
+type loadTrackingSelector interface {
+	StartRequest(peer string)
+	FinishRequest(peer string)
+}
+
+// latencyTrackingSelector is implemented by PeerSelectors (LatencySelector) that want to
+// observe completed proxy latencies. WrapHandler type-asserts h.peerSelector against
+// this so RecordLatency sees real round-trip times instead of staying test-only.
+type latencyTrackingSelector interface {
+	RecordLatency(peer string, latency time.Duration)
+}
+
+// RandomSelector picks a uniformly random peer among those serving the GVR.
+type RandomSelector struct {
+	// Rand is the source of randomness; defaults to a process-global math/rand source
+	// if nil, so tests can inject a seeded one for deterministic distributions.
+	Rand *rand.Rand
+}
+
+// Pick implements PeerSelector.
+func (s *RandomSelector) Pick(gvr schema.GroupVersionResource, peers []string) (string, error) {
+	if len(peers) == 0 {
+		return "", fmt.Errorf("no peers available for %s", gvr)
+	}
+	if s.Rand != nil {
+		return peers[s.Rand.Intn(len(peers))], nil
+	}
+	return peers[rand.Intn(len(peers))], nil
+}
+
+// RoundRobinSelector cycles through the peers serving a GVR in the order they're
+// passed to Pick, independently per GVR so one busy GVR's cursor doesn't skew another's.
+type RoundRobinSelector struct {
+	mu      sync.Mutex
+	cursors map[schema.GroupVersionResource]int
+}
+
+// NewRoundRobinSelector returns a RoundRobinSelector with no prior state.
+func NewRoundRobinSelector() *RoundRobinSelector {
+	return &RoundRobinSelector{cursors: map[schema.GroupVersionResource]int{}}
+}
+
+// Pick implements PeerSelector.
+func (s *RoundRobinSelector) Pick(gvr schema.GroupVersionResource, peers []string) (string, error) {
+	if len(peers) == 0 {
+		return "", fmt.Errorf("no peers available for %s", gvr)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx := s.cursors[gvr] % len(peers)
+	s.cursors[gvr] = idx + 1
+	return peers[idx], nil
+}
+
+// LeastLoadedSelector picks the peer with the fewest in-flight requests, as tracked by
+// StartRequest/FinishRequest calls around a proxy attempt. Peers never seen by
+// StartRequest are treated as having zero in-flight requests.
+type LeastLoadedSelector struct {
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+// NewLeastLoadedSelector returns a LeastLoadedSelector with no prior load recorded.
+func NewLeastLoadedSelector() *LeastLoadedSelector {
+	return &LeastLoadedSelector{inFlight: map[string]int{}}
+}
+
+// StartRequest records that a request is about to be proxied to peer.
+func (s *LeastLoadedSelector) StartRequest(peer string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight[peer]++
+}
+
+// FinishRequest records that a request proxied to peer has completed.
+func (s *LeastLoadedSelector) FinishRequest(peer string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inFlight[peer] > 0 {
+		s.inFlight[peer]--
+	}
+}
+
+// Pick implements PeerSelector. Ties are broken by the order peers appear in.
+func (s *LeastLoadedSelector) Pick(gvr schema.GroupVersionResource, peers []string) (string, error) {
+	if len(peers) == 0 {
+		return "", fmt.Errorf("no peers available for %s", gvr)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	best := peers[0]
+	bestLoad := s.inFlight[best]
+	for _, p := range peers[1:] {
+		if load := s.inFlight[p]; load < bestLoad {
+			best, bestLoad = p, load
+		}
+	}
+	return best, nil
+}
+
+// LatencySelector picks the peer with the lowest exponentially-weighted moving average
+// of recent proxy latencies, as recorded by RecordLatency. A peer with no recorded
+// latency is treated as having zero latency, so unproven peers get an initial chance
+// ahead of ones with an established slow EWMA.
+type LatencySelector struct {
+	// Alpha weights how much a new observation moves the EWMA, in (0, 1]. A value <= 0
+	// uses 0.2.
+	Alpha float64
+
+	mu   sync.Mutex
+	ewma map[string]time.Duration
+}
+
+// NewLatencySelector returns a LatencySelector with no prior latency recorded.
+func NewLatencySelector(alpha float64) *LatencySelector {
+	return &LatencySelector{Alpha: alpha, ewma: map[string]time.Duration{}}
+}
+
+func (s *LatencySelector) alpha() float64 {
+	if s.Alpha <= 0 {
+		return 0.2
+	}
+	return s.Alpha
+}
+
+// RecordLatency folds a newly observed proxy latency to peer into its EWMA.
+func (s *LatencySelector) RecordLatency(peer string, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, ok := s.ewma[peer]
+	if !ok {
+		s.ewma[peer] = latency
+		return
+	}
+	a := s.alpha()
+	s.ewma[peer] = time.Duration(a*float64(latency) + (1-a)*float64(prev))
+}
+
+// Pick implements PeerSelector. Ties are broken by the order peers appear in.
+func (s *LatencySelector) Pick(gvr schema.GroupVersionResource, peers []string) (string, error) {
+	if len(peers) == 0 {
+		return "", fmt.Errorf("no peers available for %s", gvr)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	best := peers[0]
+	bestLatency := s.ewma[best]
+	for _, p := range peers[1:] {
+		if latency := s.ewma[p]; latency < bestLatency {
+			best, bestLatency = p, latency
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peerproxy
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var testGVR = schema.GroupVersionResource{Group: "testgroup", Version: "v1", Resource: "testresources"}
+
+func TestRandomSelectorDistribution(t *testing.T) {
+	peers := []string{"peer-1", "peer-2", "peer-3"}
+	s := &RandomSelector{Rand: rand.New(rand.NewSource(1))}
+
+	counts := map[string]int{}
+	for i := 0; i < 3000; i++ {
+		peer, err := s.Pick(testGVR, peers)
+		require.NoError(t, err)
+		counts[peer]++
+	}
+
+	for _, peer := range peers {
+		assert.Greater(t, counts[peer], 0, "every peer serving the GVR should eventually be picked")
+	}
+}
+
+func TestRoundRobinSelectorCyclesPerGVR(t *testing.T) {
+	s := NewRoundRobinSelector()
+	peers := []string{"peer-1", "peer-2", "peer-3"}
+
+	var got []string
+	for i := 0; i < 6; i++ {
+		peer, err := s.Pick(testGVR, peers)
+		require.NoError(t, err)
+		got = append(got, peer)
+	}
+	assert.Equal(t, []string{"peer-1", "peer-2", "peer-3", "peer-1", "peer-2", "peer-3"}, got)
+
+	otherGVR := schema.GroupVersionResource{Group: "other", Version: "v1", Resource: "others"}
+	peer, err := s.Pick(otherGVR, peers)
+	require.NoError(t, err)
+	assert.Equal(t, "peer-1", peer, "a different GVR should have its own cursor")
+}
+
+func TestLeastLoadedSelectorPicksFewestInFlight(t *testing.T) {
+	s := NewLeastLoadedSelector()
+	peers := []string{"peer-1", "peer-2", "peer-3"}
+
+	s.StartRequest("peer-1")
+	s.StartRequest("peer-1")
+	s.StartRequest("peer-2")
+
+	peer, err := s.Pick(testGVR, peers)
+	require.NoError(t, err)
+	assert.Equal(t, "peer-3", peer, "the peer with zero in-flight requests should be picked")
+
+	s.FinishRequest("peer-1")
+	s.FinishRequest("peer-1")
+	peer, err = s.Pick(testGVR, []string{"peer-1", "peer-2"})
+	require.NoError(t, err)
+	assert.Equal(t, "peer-1", peer, "finishing peer-1's requests should make it eligible again")
+}
+
+func TestLatencySelectorPicksLowestEWMA(t *testing.T) {
+	s := NewLatencySelector(0.5)
+	s.RecordLatency("peer-1", 100*time.Millisecond)
+	s.RecordLatency("peer-2", 10*time.Millisecond)
+
+	peer, err := s.Pick(testGVR, []string{"peer-1", "peer-2", "peer-3"})
+	require.NoError(t, err)
+	assert.Equal(t, "peer-3", peer, "an unproven peer with no recorded latency should be preferred over a known-slow one")
+
+	s.RecordLatency("peer-3", 200*time.Millisecond)
+	peer, err = s.Pick(testGVR, []string{"peer-1", "peer-2", "peer-3"})
+	require.NoError(t, err)
+	assert.Equal(t, "peer-2", peer)
+}
+
+func TestSelectorsErrorOnNoPeers(t *testing.T) {
+	selectors := []PeerSelector{
+		&RandomSelector{},
+		NewRoundRobinSelector(),
+		NewLeastLoadedSelector(),
+		NewLatencySelector(0),
+	}
+	for _, s := range selectors {
+		_, err := s.Pick(testGVR, nil)
+		assert.Error(t, err)
+	}
+}
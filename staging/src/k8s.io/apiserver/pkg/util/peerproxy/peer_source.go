@@ -0,0 +1,259 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peerproxy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	coordinationv1informers "k8s.io/client-go/informers/coordination/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// PeerEventType is the kind of change a PeerSource reported through Watch.
+type PeerEventType string
+
+const (
+	// PeerEventAdd means ref was newly added.
+	PeerEventAdd PeerEventType = "add"
+	// PeerEventUpdate means ref's endpoint or GVRs changed.
+	PeerEventUpdate PeerEventType = "update"
+	// PeerEventDelete means ref is no longer a peer.
+	PeerEventDelete PeerEventType = "delete"
+)
+
+// PeerRef identifies a single peer apiserver, regardless of which PeerSource surfaced
+// it - a local identity Lease, a statically configured endpoint, or an out-of-cluster
+// federation registry.
+type PeerRef struct {
+	// ID is the peer's identity, used as the key into the discovery cache and as the
+	// peer label on metrics. For a LeasePeerSource this is the Lease's name.
+	ID string
+	// Endpoint is the host:port the peer can be reached at.
+	Endpoint string
+}
+
+// PeerEvent is a single change reported by a PeerSource's Watch channel.
+type PeerEvent struct {
+	Type PeerEventType
+	Peer PeerRef
+}
+
+// PeerSource supplies the set of peer apiservers a peerProxyHandler should discover
+// from and proxy to. Implementations include a Lease-informer-backed source for peers
+// in the local cluster, a statically configured source, and a union of sources for
+// federating across both. By default peerProxyHandler does not use a PeerSource: it
+// lists the Lease informer passed to NewPeerProxyHandler directly. Call SetPeerSource to
+// sync from a PeerSource instead, e.g. a MultiPeerSource that also federates peers from
+// other sources, such as sibling clusters whose Leases aren't visible locally.
+type PeerSource interface {
+	// List returns every peer currently known to this source.
+	List(ctx context.Context) ([]PeerRef, error)
+	// Watch returns a channel of incremental changes to this source's peer set. The
+	// channel is closed when ctx is done.
+	Watch(ctx context.Context) (<-chan PeerEvent, error)
+}
+
+// LeasePeerSource sources peers from coordination.k8s.io/Lease objects in the local
+// apiserver, the same mechanism peerProxyHandler has always discovered peers through.
+type LeasePeerSource struct {
+	leaseInformer coordinationv1informers.LeaseInformer
+	// ToEndpoint extracts the host:port a lease's holder can be reached at.
+	ToEndpoint func(lease *coordinationv1.Lease) (string, error)
+}
+
+// NewLeasePeerSource returns a LeasePeerSource reading leases from leaseInformer and
+// resolving each one's endpoint with toEndpoint.
+func NewLeasePeerSource(leaseInformer coordinationv1informers.LeaseInformer, toEndpoint func(lease *coordinationv1.Lease) (string, error)) *LeasePeerSource {
+	return &LeasePeerSource{leaseInformer: leaseInformer, ToEndpoint: toEndpoint}
+}
+
+// List implements PeerSource.
+func (s *LeasePeerSource) List(ctx context.Context) ([]PeerRef, error) {
+	leases, err := s.leaseInformer.Lister().List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("listing peer identity leases: %w", err)
+	}
+
+	refs := make([]PeerRef, 0, len(leases))
+	for _, lease := range leases {
+		endpoint, err := s.ToEndpoint(lease)
+		if err != nil {
+			return nil, fmt.Errorf("resolving endpoint for lease %s: %w", lease.Name, err)
+		}
+		refs = append(refs, PeerRef{ID: lease.Name, Endpoint: endpoint})
+	}
+	return refs, nil
+}
+
+// Watch implements PeerSource by translating the lease informer's own event handler
+// callbacks into PeerEvents. Delivery to a slow consumer blocks until the event is
+// received or ctx is done, and a WaitGroup tracks in-flight callback sends so the channel is only
+// closed once every callback that started before teardown has returned - otherwise a
+// callback racing with ctx's cancellation could send on an already-closed channel.
+func (s *LeasePeerSource) Watch(ctx context.Context) (<-chan PeerEvent, error) {
+	events := make(chan PeerEvent, 100)
+	var inFlight sync.WaitGroup
+
+	toRef := func(obj interface{}) (PeerRef, bool) {
+		lease, ok := obj.(*coordinationv1.Lease)
+		if !ok {
+			tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+			if !ok {
+				return PeerRef{}, false
+			}
+			lease, ok = tombstone.Obj.(*coordinationv1.Lease)
+			if !ok {
+				return PeerRef{}, false
+			}
+		}
+		endpoint, err := s.ToEndpoint(lease)
+		if err != nil {
+			return PeerRef{}, false
+		}
+		return PeerRef{ID: lease.Name, Endpoint: endpoint}, true
+	}
+
+	send := func(evType PeerEventType, obj interface{}) {
+		inFlight.Add(1)
+		defer inFlight.Done()
+
+		ref, ok := toRef(obj)
+		if !ok {
+			return
+		}
+		select {
+		case events <- PeerEvent{Type: evType, Peer: ref}:
+		case <-ctx.Done():
+		}
+	}
+
+	registration, err := s.leaseInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { send(PeerEventAdd, obj) },
+		UpdateFunc: func(oldObj, newObj interface{}) { send(PeerEventUpdate, newObj) },
+		DeleteFunc: func(obj interface{}) { send(PeerEventDelete, obj) },
+	})
+	if err != nil {
+		close(events)
+		return nil, fmt.Errorf("registering lease event handler: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = s.leaseInformer.Informer().RemoveEventHandler(registration)
+		inFlight.Wait()
+		close(events)
+	}()
+
+	return events, nil
+}
+
+// StaticPeerSource sources a fixed, caller-provided list of peers, typically loaded
+// from a YAML or JSON config file. It never changes after construction, so Watch
+// returns a channel that is immediately closed once ctx is done without ever emitting
+// an event.
+type StaticPeerSource struct {
+	peers []PeerRef
+}
+
+// NewStaticPeerSource returns a StaticPeerSource for the given fixed peer list.
+func NewStaticPeerSource(peers []PeerRef) *StaticPeerSource {
+	return &StaticPeerSource{peers: peers}
+}
+
+// List implements PeerSource.
+func (s *StaticPeerSource) List(ctx context.Context) ([]PeerRef, error) {
+	out := make([]PeerRef, len(s.peers))
+	copy(out, s.peers)
+	return out, nil
+}
+
+// Watch implements PeerSource.
+func (s *StaticPeerSource) Watch(ctx context.Context) (<-chan PeerEvent, error) {
+	events := make(chan PeerEvent)
+	go func() {
+		<-ctx.Done()
+		close(events)
+	}()
+	return events, nil
+}
+
+// MultiPeerSource unions the peers of several PeerSources, for federating a cluster's
+// own Lease-discovered peers with peers from other sources such as sibling clusters
+// whose Leases aren't visible locally.
+type MultiPeerSource struct {
+	sources []PeerSource
+}
+
+// NewMultiPeerSource returns a MultiPeerSource that unions sources.
+func NewMultiPeerSource(sources ...PeerSource) *MultiPeerSource {
+	return &MultiPeerSource{sources: sources}
+}
+
+// List implements PeerSource by concatenating every source's List result. A peer ID
+// reported by more than one source appears once per source; callers that need a single
+// entry per ID should dedupe by PeerRef.ID, preferring whichever source they trust most.
+func (s *MultiPeerSource) List(ctx context.Context) ([]PeerRef, error) {
+	var all []PeerRef
+	for _, src := range s.sources {
+		refs, err := src.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, refs...)
+	}
+	return all, nil
+}
+
+// Watch implements PeerSource by fanning in every source's Watch channel into one.
+func (s *MultiPeerSource) Watch(ctx context.Context) (<-chan PeerEvent, error) {
+	merged := make(chan PeerEvent, 100)
+
+	channels := make([]<-chan PeerEvent, 0, len(s.sources))
+	for _, src := range s.sources {
+		ch, err := src.Watch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, ch)
+	}
+
+	var pending int
+	done := make(chan struct{}, len(channels))
+	for _, ch := range channels {
+		pending++
+		ch := ch
+		go func() {
+			for ev := range ch {
+				merged <- ev
+			}
+			done <- struct{}{}
+		}()
+	}
+
+	go func() {
+		for i := 0; i < pending; i++ {
+			<-done
+		}
+		close(merged)
+	}()
+
+	return merged, nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package peerproxy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func toTestEndpoint(lease *coordinationv1.Lease) (string, error) {
+	if lease.Spec.HolderIdentity == nil {
+		return "", fmt.Errorf("lease %s has no holder identity", lease.Name)
+	}
+	return *lease.Spec.HolderIdentity + ":6443", nil
+}
+
+func TestStaticPeerSourceList(t *testing.T) {
+	s := NewStaticPeerSource([]PeerRef{
+		{ID: "static-1", Endpoint: "10.0.0.1:6443"},
+		{ID: "static-2", Endpoint: "10.0.0.2:6443"},
+	})
+
+	refs, err := s.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []PeerRef{
+		{ID: "static-1", Endpoint: "10.0.0.1:6443"},
+		{ID: "static-2", Endpoint: "10.0.0.2:6443"},
+	}, refs)
+}
+
+func TestLeasePeerSourceList(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: "remote-1"},
+			Spec:       coordinationv1.LeaseSpec{HolderIdentity: proto.String("holder-1")},
+		},
+	)
+	factory := informers.NewSharedInformerFactory(fakeClient, 0)
+	leaseInformer := factory.Coordination().V1().Leases()
+	factory.Start(context.Background().Done())
+	factory.WaitForCacheSync(context.Background().Done())
+
+	s := NewLeasePeerSource(leaseInformer, toTestEndpoint)
+	refs, err := s.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, refs, 1)
+	assert.Equal(t, PeerRef{ID: "remote-1", Endpoint: "holder-1:6443"}, refs[0])
+}
+
+func TestMultiSourceDiscovery(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Name: "in-cluster-1"},
+			Spec:       coordinationv1.LeaseSpec{HolderIdentity: proto.String("holder-1")},
+		},
+	)
+	factory := informers.NewSharedInformerFactory(fakeClient, 0)
+	leaseInformer := factory.Coordination().V1().Leases()
+	factory.Start(context.Background().Done())
+	factory.WaitForCacheSync(context.Background().Done())
+
+	leaseSource := NewLeasePeerSource(leaseInformer, toTestEndpoint)
+	staticSource := NewStaticPeerSource([]PeerRef{
+		{ID: "federated-1", Endpoint: "peer.sibling-cluster.example:6443"},
+	})
+
+	multi := NewMultiPeerSource(leaseSource, staticSource)
+	refs, err := multi.List(context.Background())
+	require.NoError(t, err)
+
+	ids := map[string]string{}
+	for _, ref := range refs {
+		ids[ref.ID] = ref.Endpoint
+	}
+	assert.Equal(t, map[string]string{
+		"in-cluster-1": "holder-1:6443",
+		"federated-1":  "peer.sibling-cluster.example:6443",
+	}, ids)
+}
+
+func TestMultiPeerSourceWatchFansInEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	staticA := NewStaticPeerSource([]PeerRef{{ID: "a", Endpoint: "a:1"}})
+	staticB := NewStaticPeerSource([]PeerRef{{ID: "b", Endpoint: "b:1"}})
+	multi := NewMultiPeerSource(staticA, staticB)
+
+	events, err := multi.Watch(ctx)
+	require.NoError(t, err)
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok, "the merged channel should close once every source's channel closes")
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for merged channel to close")
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	restclient "k8s.io/client-go/rest"
+	restfake "k8s.io/client-go/rest/fake"
+	cgtesting "k8s.io/client-go/testing"
+)
+
+var podsResource = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+
+// FakePods is a fake implementation of PodInterface's pod-log/exec/attach related
+// methods, on top of the generated FakePods CRUD methods.
+type FakePods struct {
+	Fake *FakeCoreV1
+	ns   string
+}
+
+func newFakePods(fake *FakeCoreV1, namespace string) *FakePods {
+	return &FakePods{Fake: fake, ns: namespace}
+}
+
+// LogStream lets a ("get", "pods/log") reactor hand GetLogs a live io.ReadCloser -
+// e.g. one side of an io.Pipe the test feeds chunks into over time and closes once a
+// simulated Follow watch should end - instead of a single canned byte blob.
+type LogStream struct {
+	runtime.Unknown
+	Reader io.ReadCloser
+}
+
+// getLogsAction implements cgtesting.GetAction and PodLogOptionsAction so that reactors
+// can inspect the pod name (GetName) as well as the full PodLogOptions
+// (GetPodLogOptions), matching how a real "pods/log" subresource GET is observed.
+type getLogsAction struct {
+	cgtesting.ActionImpl
+	name  string
+	value interface{}
+}
+
+func (g getLogsAction) GetName() string       { return g.name }
+func (g getLogsAction) GetValue() interface{} { return g.value }
+
+// GetPodLogOptions returns the PodLogOptions the GetLogs call was made with, so a
+// reactor can branch on Follow/SinceTime/SinceSeconds/TailLines without type-asserting
+// GetValue() itself.
+func (g getLogsAction) GetPodLogOptions() *v1.PodLogOptions {
+	opts, _ := g.value.(*v1.PodLogOptions)
+	return opts
+}
+
+// PodLogOptionsAction is implemented by the action GetLogs records.
+type PodLogOptionsAction interface {
+	cgtesting.Action
+	GetPodLogOptions() *v1.PodLogOptions
+}
+
+// GetLogs builds a fake streaming request for a pod's logs. Tests can use a reactor on
+// ("get", "pods/log") to simulate an error, or to supply a custom response:
+//   - returning a *runtime.Unknown makes its Raw bytes the log content.
+//   - returning a *LogStream makes its Reader the response body directly, so req.Stream
+//     never buffers it - useful for simulating Follow with a pipe that emits chunks over
+//     time and is closed by the test once the simulated watch should end.
+//
+// When no reactor is registered, GetLogs falls back to a default reactor that filters a
+// canned in-memory log slice by opts.SinceTime, opts.SinceSeconds and opts.TailLines,
+// the same way a real kubelet filters its log file before streaming it back.
+func (c *FakePods) GetLogs(name string, opts *v1.PodLogOptions) *restclient.Request {
+	action := getLogsAction{
+		ActionImpl: cgtesting.ActionImpl{
+			Namespace:   c.ns,
+			Verb:        "get",
+			Resource:    podsResource,
+			Subresource: "log",
+		},
+		name:  name,
+		value: opts,
+	}
+
+	var body io.ReadCloser
+	obj, err := c.Fake.Invokes(action, &v1.Pod{})
+	switch v := obj.(type) {
+	case *LogStream:
+		body = v.Reader
+	case *runtime.Unknown:
+		body = io.NopCloser(strings.NewReader(string(v.Raw)))
+	case *v1.Pod:
+		// No reactor claimed the action; Invokes returned back the default object we
+		// passed in, so fall back to the canned, filtered log content.
+		body = io.NopCloser(strings.NewReader(filterFakeLogLines(defaultFakeLogLines(), opts)))
+	}
+	if body == nil {
+		body = io.NopCloser(strings.NewReader(""))
+	}
+
+	fakeClient := &restfake.RESTClient{
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		GroupVersion:         v1.SchemeGroupVersion,
+		VersionedAPIPath:     "/api/v1/fake",
+		Err:                  err,
+		Resp: &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       body,
+		},
+	}
+	return fakeClient.Request().
+		Verb("GET").
+		Namespace(c.ns).
+		Resource("pods").
+		Name(name).
+		SubResource("log").
+		VersionedParams(opts, scheme.ParameterCodec)
+}
+
+// fakeLogLine is one line of the canned log content the default ("get", "pods/log")
+// reactor serves when no test reactor is registered.
+type fakeLogLine struct {
+	timestamp time.Time
+	text      string
+}
+
+// defaultFakeLogLines returns three lines timestamped relative to now, so
+// SinceTime/SinceSeconds filtering against them behaves the way it would against a real,
+// recently-written log file instead of against a fixed date tests would otherwise always
+// filter out entirely.
+func defaultFakeLogLines() []fakeLogLine {
+	now := time.Now()
+	return []fakeLogLine{
+		{timestamp: now.Add(-3 * time.Second), text: "fake log line 1"},
+		{timestamp: now.Add(-2 * time.Second), text: "fake log line 2"},
+		{timestamp: now.Add(-1 * time.Second), text: "fake log line 3"},
+	}
+}
+
+// filterFakeLogLines applies opts.SinceTime, opts.SinceSeconds and opts.TailLines to
+// lines, in that order, and joins what remains into a newline-terminated log body.
+func filterFakeLogLines(lines []fakeLogLine, opts *v1.PodLogOptions) string {
+	if opts != nil && opts.SinceTime != nil {
+		lines = dropBefore(lines, opts.SinceTime.Time)
+	}
+	if opts != nil && opts.SinceSeconds != nil {
+		lines = dropBefore(lines, time.Now().Add(-time.Duration(*opts.SinceSeconds)*time.Second))
+	}
+	if opts != nil && opts.TailLines != nil && int(*opts.TailLines) < len(lines) {
+		lines = lines[len(lines)-int(*opts.TailLines):]
+	}
+
+	var sb strings.Builder
+	for _, l := range lines {
+		sb.WriteString(l.text)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func dropBefore(lines []fakeLogLine, cutoff time.Time) []fakeLogLine {
+	kept := make([]fakeLogLine, 0, len(lines))
+	for _, l := range lines {
+		if !l.timestamp.Before(cutoff) {
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
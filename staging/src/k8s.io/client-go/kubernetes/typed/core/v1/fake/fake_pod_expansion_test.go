@@ -21,9 +21,12 @@ import (
 	"context"
 	"errors"
 	"io"
+	"strings"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	cgtesting "k8s.io/client-go/testing"
 )
@@ -105,3 +108,106 @@ func TestFakePodsGetLogsReactorResponse(t *testing.T) {
 		t.Fatalf("expected logs %q, got %q", expectedLogs, string(logs))
 	}
 }
+
+func TestFakePodsGetLogsDefaultReactorFiltersTailLines(t *testing.T) {
+	fp := newFakePods(&FakeCoreV1{Fake: &cgtesting.Fake{}}, "default")
+	var tailLines int64 = 1
+	req := fp.GetLogs("foo", &corev1.PodLogOptions{TailLines: &tailLines})
+	body, err := req.Stream(context.Background())
+	if err != nil {
+		t.Fatalf("Stream pod logs: %v", err)
+	}
+	defer body.Close()
+
+	logs, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("Read pod logs: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(string(logs), "\n"), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected TailLines to leave exactly 1 line, got %d: %q", len(lines), logs)
+	}
+}
+
+func TestFakePodsGetLogsDefaultReactorFiltersSinceTime(t *testing.T) {
+	fp := newFakePods(&FakeCoreV1{Fake: &cgtesting.Fake{}}, "default")
+	sinceTime := metav1.NewTime(time.Now())
+	req := fp.GetLogs("foo", &corev1.PodLogOptions{SinceTime: &sinceTime})
+	body, err := req.Stream(context.Background())
+	if err != nil {
+		t.Fatalf("Stream pod logs: %v", err)
+	}
+	defer body.Close()
+
+	logs, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("Read pod logs: %v", err)
+	}
+	if string(logs) != "" {
+		t.Fatalf("expected SinceTime set to now to leave no log lines, got %q", logs)
+	}
+}
+
+func TestFakePodsGetLogsReactorPodLogOptionsHelper(t *testing.T) {
+	fake := &cgtesting.Fake{}
+	fp := newFakePods(&FakeCoreV1{Fake: fake}, "default")
+	var tailLines int64 = 5
+	fake.PrependReactor("get", "pods/log", func(action cgtesting.Action) (bool, runtime.Object, error) {
+		optsAction, ok := action.(PodLogOptionsAction)
+		if !ok {
+			t.Fatalf("expected PodLogOptionsAction, got %T", action)
+		}
+		opts := optsAction.GetPodLogOptions()
+		if opts.TailLines == nil || *opts.TailLines != tailLines {
+			t.Fatalf("expected TailLines %d, got %v", tailLines, opts.TailLines)
+		}
+		return true, &runtime.Unknown{Raw: []byte("ok")}, nil
+	})
+
+	req := fp.GetLogs("foo", &corev1.PodLogOptions{TailLines: &tailLines})
+	body, err := req.Stream(context.Background())
+	if err != nil {
+		t.Fatalf("Stream pod logs: %v", err)
+	}
+	body.Close()
+}
+
+func TestFakePodsGetLogsReactorLogStreamFollow(t *testing.T) {
+	fake := &cgtesting.Fake{}
+	fp := newFakePods(&FakeCoreV1{Fake: fake}, "default")
+
+	pr, pw := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-ctx.Done()
+		pw.Close()
+	}()
+
+	fake.PrependReactor("get", "pods/log", func(action cgtesting.Action) (bool, runtime.Object, error) {
+		optsAction := action.(PodLogOptionsAction)
+		if !optsAction.GetPodLogOptions().Follow {
+			t.Fatal("expected Follow to be true")
+		}
+		return true, &LogStream{Reader: pr}, nil
+	})
+
+	req := fp.GetLogs("foo", &corev1.PodLogOptions{Follow: true})
+	body, err := req.Stream(context.Background())
+	if err != nil {
+		t.Fatalf("Stream pod logs: %v", err)
+	}
+	defer body.Close()
+
+	go func() {
+		pw.Write([]byte("chunk one\n"))
+		cancel()
+	}()
+
+	logs, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("Read pod logs: %v", err)
+	}
+	if string(logs) != "chunk one\n" {
+		t.Fatalf("expected streamed chunk %q, got %q", "chunk one\n", logs)
+	}
+}
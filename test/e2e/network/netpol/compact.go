@@ -0,0 +1,195 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netpol
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+var compactPolicies = flag.Bool("compact-policies", false,
+	"merge generated NetworkPolicies' rules that share identical peers or identical ports before creating them, to measure rule-count reduction on real CNIs")
+
+// CompactPolicy returns a copy of p with its Ingress and Egress rules merged wherever
+// doing so doesn't change what the policy allows: rules whose peer sets (From/To) are
+// identical are combined into one rule whose Ports is the union of both, and rules
+// whose Ports are identical are combined into one rule whose peers are the union of
+// both. This mirrors the rule-combining pass libcalico-go applies when converting
+// NetworkPolicies (projectcalico/libcalico-go), reducing the number of rules a CNI has
+// to materialize. CreatePolicy and UpdatePolicy apply it automatically when the suite
+// is run with --compact-policies.
+func CompactPolicy(p *networkingv1.NetworkPolicy) *networkingv1.NetworkPolicy {
+	out := p.DeepCopy()
+	out.Spec.Ingress = compactIngressRules(out.Spec.Ingress)
+	out.Spec.Egress = compactEgressRules(out.Spec.Egress)
+	return out
+}
+
+// compactIngressRules repeatedly merges rules by peers then by ports until a pass
+// makes no further change, so the result is a fixed point: compacting it again is a
+// no-op.
+func compactIngressRules(rules []networkingv1.NetworkPolicyIngressRule) []networkingv1.NetworkPolicyIngressRule {
+	for {
+		next := mergeIngressRulesByPeers(rules)
+		next = mergeIngressRulesByPorts(next)
+		if reflect.DeepEqual(next, rules) {
+			return next
+		}
+		rules = next
+	}
+}
+
+func compactEgressRules(rules []networkingv1.NetworkPolicyEgressRule) []networkingv1.NetworkPolicyEgressRule {
+	for {
+		next := mergeEgressRulesByPeers(rules)
+		next = mergeEgressRulesByPorts(next)
+		if reflect.DeepEqual(next, rules) {
+			return next
+		}
+		rules = next
+	}
+}
+
+func mergeIngressRulesByPeers(rules []networkingv1.NetworkPolicyIngressRule) []networkingv1.NetworkPolicyIngressRule {
+	var merged []networkingv1.NetworkPolicyIngressRule
+	index := map[string]int{}
+	for _, rule := range rules {
+		key := peerSetKey(rule.From)
+		if i, ok := index[key]; ok {
+			merged[i].Ports = unionPorts(merged[i].Ports, rule.Ports)
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, networkingv1.NetworkPolicyIngressRule{From: rule.From, Ports: rule.Ports})
+	}
+	return merged
+}
+
+func mergeIngressRulesByPorts(rules []networkingv1.NetworkPolicyIngressRule) []networkingv1.NetworkPolicyIngressRule {
+	var merged []networkingv1.NetworkPolicyIngressRule
+	index := map[string]int{}
+	for _, rule := range rules {
+		key := portSetKey(rule.Ports)
+		if i, ok := index[key]; ok {
+			merged[i].From = unionPeers(merged[i].From, rule.From)
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, networkingv1.NetworkPolicyIngressRule{From: rule.From, Ports: rule.Ports})
+	}
+	return merged
+}
+
+func mergeEgressRulesByPeers(rules []networkingv1.NetworkPolicyEgressRule) []networkingv1.NetworkPolicyEgressRule {
+	var merged []networkingv1.NetworkPolicyEgressRule
+	index := map[string]int{}
+	for _, rule := range rules {
+		key := peerSetKey(rule.To)
+		if i, ok := index[key]; ok {
+			merged[i].Ports = unionPorts(merged[i].Ports, rule.Ports)
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, networkingv1.NetworkPolicyEgressRule{To: rule.To, Ports: rule.Ports})
+	}
+	return merged
+}
+
+func mergeEgressRulesByPorts(rules []networkingv1.NetworkPolicyEgressRule) []networkingv1.NetworkPolicyEgressRule {
+	var merged []networkingv1.NetworkPolicyEgressRule
+	index := map[string]int{}
+	for _, rule := range rules {
+		key := portSetKey(rule.Ports)
+		if i, ok := index[key]; ok {
+			merged[i].To = unionPeers(merged[i].To, rule.To)
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, networkingv1.NetworkPolicyEgressRule{To: rule.To, Ports: rule.Ports})
+	}
+	return merged
+}
+
+// peerSetKey returns a canonical, order-independent string key for a set of peers, so
+// two rules listing the same peers in a different order are recognized as mergeable.
+func peerSetKey(peers []networkingv1.NetworkPolicyPeer) string {
+	items := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		items = append(items, fmt.Sprintf("%+v", peer))
+	}
+	sort.Strings(items)
+	return fmt.Sprintf("%v", items)
+}
+
+// portSetKey returns a canonical, order-independent string key for a set of ports.
+// Empty/nil Ports (meaning "all ports") gets its own distinct key so it's never
+// confused with an explicit, merely-empty-after-dedup ports list.
+func portSetKey(ports []networkingv1.NetworkPolicyPort) string {
+	if len(ports) == 0 {
+		return "<all-ports>"
+	}
+	items := make([]string, 0, len(ports))
+	for _, port := range ports {
+		items = append(items, fmt.Sprintf("%+v", port))
+	}
+	sort.Strings(items)
+	return fmt.Sprintf("%v", items)
+}
+
+// unionPorts merges two rules' Ports lists, deduplicated by value. If either side is
+// empty/nil (meaning "all ports"), the union is too, since that side already allows
+// everything the other side would add.
+func unionPorts(a, b []networkingv1.NetworkPolicyPort) []networkingv1.NetworkPolicyPort {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	seen := map[string]bool{}
+	var merged []networkingv1.NetworkPolicyPort
+	for _, port := range append(append([]networkingv1.NetworkPolicyPort{}, a...), b...) {
+		key := fmt.Sprintf("%+v", port)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		merged = append(merged, port)
+	}
+	return merged
+}
+
+// unionPeers merges two rules' peer lists, deduplicated by value. If either side is
+// empty/nil (meaning "from/to anyone"), the union is too, since that side already allows
+// everything the other side would add.
+func unionPeers(a, b []networkingv1.NetworkPolicyPeer) []networkingv1.NetworkPolicyPeer {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	seen := map[string]bool{}
+	var merged []networkingv1.NetworkPolicyPeer
+	for _, peer := range append(append([]networkingv1.NetworkPolicyPeer{}, a...), b...) {
+		key := fmt.Sprintf("%+v", peer)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		merged = append(merged, peer)
+	}
+	return merged
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netpol
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/kubernetes/test/e2e/network/netpol/generator"
+)
+
+type compactTestCase struct {
+	name          string
+	policy        *networkingv1.NetworkPolicy
+	wantRuleCount int  // expected len(Ingress)+len(Egress) after compaction
+	wantNilFrom   bool // if set, also assert the single resulting Ingress rule's From is nil (unrestricted)
+}
+
+func compactTestCases() []compactTestCase {
+	podB := networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pod": "b"}}}
+	nsY := networkingv1.NetworkPolicyPeer{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{namespaceLabelKey: "y"}}}
+	nsZ := networkingv1.NetworkPolicyPeer{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{namespaceLabelKey: "z"}}}
+	port80TCP := networkingv1.NetworkPolicyPort{Protocol: &protocolTCP, Port: &intstr.IntOrString{IntVal: 80}}
+	port81TCP := networkingv1.NetworkPolicyPort{Protocol: &protocolTCP, Port: &intstr.IntOrString{IntVal: 81}}
+
+	return []compactTestCase{
+		{
+			name: "same peers, different ports merge into one rule",
+			policy: &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "merge-ports", Namespace: "x"},
+				Spec: networkingv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pod": "a"}},
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+					Ingress: []networkingv1.NetworkPolicyIngressRule{
+						{From: []networkingv1.NetworkPolicyPeer{nsY}, Ports: []networkingv1.NetworkPolicyPort{port80TCP}},
+						{From: []networkingv1.NetworkPolicyPeer{nsY}, Ports: []networkingv1.NetworkPolicyPort{port81TCP}},
+					},
+				},
+			},
+			wantRuleCount: 1,
+		},
+		{
+			name: "same ports, different peers merge into one rule",
+			policy: &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "merge-peers", Namespace: "x"},
+				Spec: networkingv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pod": "a"}},
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+					Ingress: []networkingv1.NetworkPolicyIngressRule{
+						{From: []networkingv1.NetworkPolicyPeer{nsY}, Ports: []networkingv1.NetworkPolicyPort{port80TCP}},
+						{From: []networkingv1.NetworkPolicyPeer{nsZ}, Ports: []networkingv1.NetworkPolicyPort{port80TCP}},
+					},
+				},
+			},
+			wantRuleCount: 1,
+		},
+		{
+			name: "an unrestricted rule absorbs a narrower rule for the same peers",
+			policy: &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "absorb-all-ports", Namespace: "x"},
+				Spec: networkingv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pod": "a"}},
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+					Ingress: []networkingv1.NetworkPolicyIngressRule{
+						{From: []networkingv1.NetworkPolicyPeer{nsY}},
+						{From: []networkingv1.NetworkPolicyPeer{nsY}, Ports: []networkingv1.NetworkPolicyPort{port80TCP}},
+					},
+				},
+			},
+			wantRuleCount: 1,
+		},
+		{
+			name: "an unrestricted rule absorbs a narrower rule for the same ports",
+			policy: &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "absorb-all-peers", Namespace: "x"},
+				Spec: networkingv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pod": "a"}},
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+					Ingress: []networkingv1.NetworkPolicyIngressRule{
+						{Ports: []networkingv1.NetworkPolicyPort{port80TCP}},
+						{From: []networkingv1.NetworkPolicyPeer{nsY}, Ports: []networkingv1.NetworkPolicyPort{port80TCP}},
+					},
+				},
+			},
+			wantRuleCount: 1,
+			wantNilFrom:   true,
+		},
+		{
+			name: "rules that share neither peers nor ports are left alone",
+			policy: &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "no-merge", Namespace: "x"},
+				Spec: networkingv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pod": "a"}},
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+					Ingress: []networkingv1.NetworkPolicyIngressRule{
+						{From: []networkingv1.NetworkPolicyPeer{nsY}, Ports: []networkingv1.NetworkPolicyPort{port80TCP}},
+						{From: []networkingv1.NetworkPolicyPeer{nsZ}, Ports: []networkingv1.NetworkPolicyPort{port81TCP}},
+					},
+				},
+			},
+			wantRuleCount: 2,
+		},
+		{
+			name: "egress rules merge the same way",
+			policy: &networkingv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "merge-egress", Namespace: "x"},
+				Spec: networkingv1.NetworkPolicySpec{
+					PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pod": "a"}},
+					PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+					Egress: []networkingv1.NetworkPolicyEgressRule{
+						{To: []networkingv1.NetworkPolicyPeer{podB}, Ports: []networkingv1.NetworkPolicyPort{port80TCP}},
+						{To: []networkingv1.NetworkPolicyPeer{podB}, Ports: []networkingv1.NetworkPolicyPort{port81TCP}},
+					},
+				},
+			},
+			wantRuleCount: 1,
+		},
+	}
+}
+
+func TestCompactPolicyMergesAndIsIdempotent(t *testing.T) {
+	for _, tc := range compactTestCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			once := CompactPolicy(tc.policy)
+			if got := len(once.Spec.Ingress) + len(once.Spec.Egress); got != tc.wantRuleCount {
+				t.Errorf("got %d rules after compaction, want %d: %+v", got, tc.wantRuleCount, once.Spec)
+			}
+			if tc.wantNilFrom && once.Spec.Ingress[0].From != nil {
+				t.Errorf("got From %+v, want nil (unrestricted)", once.Spec.Ingress[0].From)
+			}
+
+			twice := CompactPolicy(once)
+			if !reflect.DeepEqual(once, twice) {
+				t.Errorf("CompactPolicy is not idempotent:\nonce:  %+v\ntwice: %+v", once.Spec, twice.Spec)
+			}
+		})
+	}
+}
+
+func compactTestTopology() generator.Topology {
+	return generator.Topology{
+		Pods: []generator.Pod{
+			{Namespace: "x", Name: "a", Labels: map[string]string{"pod": "a"}, IP: "10.0.0.1"},
+			{Namespace: "x", Name: "b", Labels: map[string]string{"pod": "b"}, IP: "10.0.0.2"},
+			{Namespace: "y", Name: "a", Labels: map[string]string{"pod": "a"}, IP: "10.0.1.1"},
+			{Namespace: "z", Name: "a", Labels: map[string]string{"pod": "a"}, IP: "10.0.2.1"},
+		},
+		NamespaceLabels: map[string]map[string]string{
+			"x": {namespaceLabelKey: "x"},
+			"y": {namespaceLabelKey: "y"},
+			"z": {namespaceLabelKey: "z"},
+		},
+	}
+}
+
+// TestCompactPolicyPreservesReachability checks behavioral equivalence: for every
+// (src, dst, port, protocol) the generator package's analytical simulator considers,
+// the original and compacted policy must agree.
+func TestCompactPolicyPreservesReachability(t *testing.T) {
+	topo := compactTestTopology()
+	ports := []int32{80, 81}
+	protocols := []v1.Protocol{v1.ProtocolTCP, v1.ProtocolUDP}
+
+	for _, tc := range compactTestCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			compacted := CompactPolicy(tc.policy)
+			for _, port := range ports {
+				for _, protocol := range protocols {
+					before := generator.Simulate(topo, []*networkingv1.NetworkPolicy{tc.policy}, port, protocol)
+					after := generator.Simulate(topo, []*networkingv1.NetworkPolicy{compacted}, port, protocol)
+					if !reflect.DeepEqual(before, after) {
+						t.Fatalf("compacting changed reachability at port %d/%s:\nbefore: %+v\nafter:  %+v", port, protocol, before, after)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netpol
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// convergenceBudget is the deadline the convergence f.It case in network_policy.go
+// allows a policy create/update to take effect in the dataplane before failing, so a CI
+// job running against a known-slower CNI can raise it without editing the test.
+var convergenceBudget = flag.Duration("netpol-convergence-budget", 30*time.Second,
+	"deadline ValidateConvergenceOrFail's convergence test allows a policy change to take effect in the dataplane")
+
+// ConvergenceOpts configures Reachability.Converge. A zero value polls every 500ms for
+// up to 30s.
+type ConvergenceOpts struct {
+	// PollInterval is how often each unconverged cell is re-probed. A value <= 0 uses
+	// 500ms.
+	PollInterval time.Duration
+	// Deadline bounds how long after Converge's since argument it keeps polling for every
+	// cell to converge. A value <= 0 uses 30s.
+	Deadline time.Duration
+}
+
+// ConvergenceResult is the per-cell latency Converge measured, plus the p50/p95/p99
+// aggregates over every cell that converged before the deadline. A cell missing from
+// Latency did not converge within the deadline.
+type ConvergenceResult struct {
+	Latency map[PodString]map[PodString]time.Duration `json:"-"`
+	P50     time.Duration                             `json:"p50"`
+	P95     time.Duration                             `json:"p95"`
+	P99     time.Duration                             `json:"p99"`
+	// Unconverged lists, as "from -> to" strings, every cell that never matched its
+	// expected value before the deadline elapsed.
+	Unconverged []string `json:"unconverged"`
+}
+
+// MarshalJSON flattens Latency's nested map into "from -> to" keys, since
+// encoding/json can't marshal a map keyed by another map directly in a readable way.
+func (c *ConvergenceResult) MarshalJSON() ([]byte, error) {
+	type alias ConvergenceResult
+	flat := struct {
+		alias
+		LatencyMillis map[string]int64 `json:"latencyMillis"`
+	}{alias: alias(*c), LatencyMillis: map[string]int64{}}
+	for from, tos := range c.Latency {
+		for to, d := range tos {
+			flat.LatencyMillis[fmt.Sprintf("%s -> %s", from, to)] = d.Milliseconds()
+		}
+	}
+	return json.Marshal(flat)
+}
+
+// Converge polls r's (src,dst) grid for port/protocol on opts.PollInterval, starting
+// immediately and continuing until every cell's observed connectivity matches its
+// Expected value or opts.Deadline elapses, recording for each cell the wall-clock delay
+// between since (typically the moment CreatePolicy returned) and the poll that first saw
+// it converge. It's meant for measuring dataplane convergence latency after a policy
+// create/update/delete, where a single-shot probe (see Reachability.Probe) can't tell
+// "never converged" apart from "converged quickly but we didn't measure when".
+func (r *Reachability) Converge(ctx context.Context, k8s *kubeManager, port int, protocol v1.Protocol, since time.Time, opts ConvergenceOpts) (*ConvergenceResult, error) {
+	pollInterval := opts.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = 500 * time.Millisecond
+	}
+	deadline := opts.Deadline
+	if deadline <= 0 {
+		deadline = 30 * time.Second
+	}
+
+	result := &ConvergenceResult{Latency: map[PodString]map[PodString]time.Duration{}}
+	deadlineAt := since.Add(deadline)
+
+	for {
+		if _, err := r.Probe(ctx, k8s, port, protocol, ProberOpts{}); err != nil {
+			return nil, err
+		}
+		now := clockNow()
+		_, _, diff, wrong := r.Summary()
+		for from, tos := range diff {
+			for to, isWrong := range tos {
+				if isWrong {
+					continue
+				}
+				if _, recorded := result.Latency[from]; !recorded {
+					result.Latency[from] = map[PodString]time.Duration{}
+				}
+				if _, recorded := result.Latency[from][to]; !recorded {
+					result.Latency[from][to] = now.Sub(since)
+				}
+			}
+		}
+		if wrong == 0 {
+			break
+		}
+		if now.After(deadlineAt) {
+			for from, tos := range diff {
+				for to, isWrong := range tos {
+					if isWrong {
+						result.Unconverged = append(result.Unconverged, fmt.Sprintf("%s -> %s", from, to))
+					}
+				}
+			}
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+
+	sort.Strings(result.Unconverged)
+	result.P50, result.P95, result.P99 = percentiles(result.Latency)
+	return result, nil
+}
+
+// clockNow is time.Now, split out as a variable so Converge's "when did this converge"
+// timestamping can be pinned down in a future unit test without a real clock.
+var clockNow = time.Now
+
+// percentiles returns the p50/p95/p99 of every latency in latency, flattened across
+// both map levels and sorted ascending.
+func percentiles(latency map[PodString]map[PodString]time.Duration) (p50, p95, p99 time.Duration) {
+	var all []time.Duration
+	for _, tos := range latency {
+		for _, d := range tos {
+			all = append(all, d)
+		}
+	}
+	if len(all) == 0 {
+		return 0, 0, 0
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	return percentile(all, 0.50), percentile(all, 0.95), percentile(all, 0.99)
+}
+
+// percentile returns the value at fraction p (0..1) of sorted, a sorted ascending slice.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(p * float64(len(sorted)))
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// ValidateConvergenceOrFail is ValidateOrFail's convergence-mode counterpart: instead of
+// a single probe pass, it polls until every cell of tc.Reachability converges or
+// opts.Deadline elapses, logs and attaches (via ginkgo.AddReportEntry) the per-cell
+// latency matrix and its p50/p95/p99 aggregates so a scale harness can track it over
+// time, and then fails the test if anything is left unconverged (attaching the final
+// observed matrix via PrintSummary so the failure is diagnosable).
+func ValidateConvergenceOrFail(ctx context.Context, k8s *kubeManager, tc *TestCase, since time.Time, opts ConvergenceOpts) {
+	result, err := tc.Reachability.Converge(ctx, k8s, tc.ToPort, tc.Protocol, since, opts)
+	if err != nil {
+		ginkgo.Fail(fmt.Sprintf("converging reachability for port %d/%s: %v", tc.ToPort, tc.Protocol, err))
+		return
+	}
+
+	if raw, err := json.Marshal(result); err == nil {
+		ginkgo.AddReportEntry(fmt.Sprintf("netpol-convergence-port-%d-%s", tc.ToPort, tc.Protocol), string(raw))
+	}
+	framework.Logf("convergence for port %d/%s: p50=%s p95=%s p99=%s", tc.ToPort, tc.Protocol, result.P50, result.P95, result.P99)
+
+	if len(result.Unconverged) > 0 {
+		var summary strings.Builder
+		fmt.Fprintf(&summary, "port %d: %d cell(s) did not converge within the deadline: %s\n", tc.ToPort, len(result.Unconverged), strings.Join(result.Unconverged, ", "))
+		tc.Reachability.PrintSummary(&summary)
+		ginkgo.Fail(summary.String())
+	}
+}
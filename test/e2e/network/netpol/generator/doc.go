@@ -0,0 +1,26 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package generator implements a Cyclonus-style fuzzer for the netpol e2e suite.
+// GeneratePolicy randomly builds a NetworkPolicy across the selector/peer/port axes
+// netpol's hand-written cases already cover one at a time (PodSelector and
+// NamespaceSelector shape, ipBlock with or without Except, port/endPort/protocol,
+// single vs. multiple peers, ingress/egress/both), and Simulate analytically computes
+// the Reachability truth table a set of generated policies implies by evaluating
+// Kubernetes' NetworkPolicy semantics directly, so a test can diff the simulated
+// result against real probed connectivity instead of hand-writing ExpectPeer calls
+// for every generated case.
+package generator
@@ -0,0 +1,238 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// namespaceLabelKey mirrors netpol.namespaceLabelKey: every model namespace is labeled
+// with its own name under this key, so a NamespaceSelector can select namespaces by
+// name the way a real cluster's namespace admission controller would label them.
+const namespaceLabelKey = "kubernetes.io/metadata.name"
+
+// Config bounds the axes GeneratePolicy draws from: which "pod" label values and
+// namespace names selectors/peers can reference, and which ports/protocols generated
+// NetworkPolicyPorts can restrict traffic to.
+type Config struct {
+	PodLabels  []string
+	Namespaces []string
+	Ports      []int32
+	Protocols  []v1.Protocol
+}
+
+// GeneratePolicy builds one randomized NetworkPolicy named name in namespace ns,
+// drawing its target PodSelector, its ingress and/or egress rules, and each rule's
+// peers and ports from every axis cfg and topo expose. PolicyTypes is sometimes left
+// unset so the fuzzer also exercises the omitted-PolicyTypes inference netpol's
+// OmitPolicyTypes generator helper supports; Simulate knows how to evaluate both.
+func GeneratePolicy(rng *rand.Rand, name, ns string, topo Topology, cfg Config) *networkingv1.NetworkPolicy {
+	spec := networkingv1.NetworkPolicySpec{
+		PodSelector: *randomSelector(rng, "pod", cfg.PodLabels),
+	}
+
+	wantIngress, wantEgress := randomDirections(rng)
+	if wantIngress {
+		spec.Ingress = randomIngressRules(rng, topo, cfg)
+	}
+	if wantEgress {
+		spec.Egress = randomEgressRules(rng, topo, cfg)
+	}
+	if rng.Intn(5) != 0 { // most generated policies declare PolicyTypes explicitly
+		if wantIngress {
+			spec.PolicyTypes = append(spec.PolicyTypes, networkingv1.PolicyTypeIngress)
+		}
+		if wantEgress {
+			spec.PolicyTypes = append(spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+		}
+	}
+
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+		Spec:       spec,
+	}
+}
+
+// randomDirections picks whether the generated policy governs ingress, egress, or
+// both, so a run of GeneratePolicy calls exercises all three.
+func randomDirections(rng *rand.Rand) (ingress, egress bool) {
+	switch rng.Intn(3) {
+	case 0:
+		return true, false
+	case 1:
+		return false, true
+	default:
+		return true, true
+	}
+}
+
+func randomIngressRules(rng *rand.Rand, topo Topology, cfg Config) []networkingv1.NetworkPolicyIngressRule {
+	rules := make([]networkingv1.NetworkPolicyIngressRule, 1+rng.Intn(2))
+	for i := range rules {
+		rules[i] = networkingv1.NetworkPolicyIngressRule{
+			From:  randomPeers(rng, topo, cfg),
+			Ports: randomPorts(rng, cfg),
+		}
+	}
+	return rules
+}
+
+func randomEgressRules(rng *rand.Rand, topo Topology, cfg Config) []networkingv1.NetworkPolicyEgressRule {
+	rules := make([]networkingv1.NetworkPolicyEgressRule, 1+rng.Intn(2))
+	for i := range rules {
+		rules[i] = networkingv1.NetworkPolicyEgressRule{
+			To:    randomPeers(rng, topo, cfg),
+			Ports: randomPorts(rng, cfg),
+		}
+	}
+	return rules
+}
+
+// randomPeers builds zero, one, or two peers for a rule. An empty/nil result means
+// "allow from/to anyone", matching SetSpecIngressRules/SetSpecEgressRules's
+// convention for a rule with no peers listed.
+func randomPeers(rng *rand.Rand, topo Topology, cfg Config) []networkingv1.NetworkPolicyPeer {
+	n := rng.Intn(3)
+	if n == 0 {
+		return nil
+	}
+	peers := make([]networkingv1.NetworkPolicyPeer, n)
+	for i := range peers {
+		peers[i] = randomPeer(rng, topo, cfg)
+	}
+	return peers
+}
+
+// randomPeer builds one peer: an ipBlock (with or without Except) a quarter of the
+// time when the topology has pods to draw addresses from, otherwise a PodSelector, a
+// NamespaceSelector, or both together (the cross-namespace peer form).
+func randomPeer(rng *rand.Rand, topo Topology, cfg Config) networkingv1.NetworkPolicyPeer {
+	if len(topo.Pods) > 0 && rng.Intn(4) == 0 {
+		return networkingv1.NetworkPolicyPeer{IPBlock: randomIPBlock(rng, topo)}
+	}
+	switch rng.Intn(3) {
+	case 0:
+		return networkingv1.NetworkPolicyPeer{PodSelector: randomSelector(rng, "pod", cfg.PodLabels)}
+	case 1:
+		return networkingv1.NetworkPolicyPeer{NamespaceSelector: randomSelector(rng, namespaceLabelKey, cfg.Namespaces)}
+	default:
+		return networkingv1.NetworkPolicyPeer{
+			PodSelector:       randomSelector(rng, "pod", cfg.PodLabels),
+			NamespaceSelector: randomSelector(rng, namespaceLabelKey, cfg.Namespaces),
+		}
+	}
+}
+
+// randomSelector builds a LabelSelector over key, drawing its value from values:
+// an empty selector (matches everything) a third of the time, otherwise a MatchLabels
+// or a MatchExpressions requirement using In, NotIn, Exists, or DoesNotExist.
+func randomSelector(rng *rand.Rand, key string, values []string) *metav1.LabelSelector {
+	if len(values) == 0 || rng.Intn(3) == 0 {
+		return &metav1.LabelSelector{}
+	}
+	value := values[rng.Intn(len(values))]
+	if rng.Intn(2) == 0 {
+		return &metav1.LabelSelector{MatchLabels: map[string]string{key: value}}
+	}
+	requirement := metav1.LabelSelectorRequirement{Key: key, Values: []string{value}}
+	switch rng.Intn(4) {
+	case 0:
+		requirement.Operator = metav1.LabelSelectorOpIn
+	case 1:
+		requirement.Operator = metav1.LabelSelectorOpNotIn
+	case 2:
+		requirement.Operator, requirement.Values = metav1.LabelSelectorOpExists, nil
+	default:
+		requirement.Operator, requirement.Values = metav1.LabelSelectorOpDoesNotExist, nil
+	}
+	return &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{requirement}}
+}
+
+// randomIPBlock builds an ipBlock peer anchored on a randomly chosen topology pod's
+// address: a /24 (or IPv6 /120) network around it so that, half the time, another
+// topology pod's address that happens to fall inside that network can be carved back
+// out via Except, meaningfully exercising the except-subtracts-from-CIDR axis against
+// real addresses rather than arbitrary ones.
+func randomIPBlock(rng *rand.Rand, topo Topology) *networkingv1.IPBlock {
+	base := net.ParseIP(topo.Pods[rng.Intn(len(topo.Pods))].IP)
+	if base == nil {
+		return &networkingv1.IPBlock{CIDR: "0.0.0.0/0"}
+	}
+	hostBits, networkBits := 32, 24
+	if base.To4() == nil {
+		hostBits, networkBits = 128, 120
+	}
+	_, network, err := net.ParseCIDR(fmt.Sprintf("%s/%d", base.String(), networkBits))
+	if err != nil {
+		return &networkingv1.IPBlock{CIDR: fmt.Sprintf("%s/%d", base.String(), hostBits)}
+	}
+	block := &networkingv1.IPBlock{CIDR: network.String()}
+	if rng.Intn(2) == 0 {
+		for _, pod := range topo.Pods {
+			candidate := net.ParseIP(pod.IP)
+			if candidate == nil || candidate.Equal(base) || !network.Contains(candidate) {
+				continue
+			}
+			block.Except = []string{fmt.Sprintf("%s/%d", candidate.String(), hostBits)}
+			break
+		}
+	}
+	return block
+}
+
+// randomPorts builds zero or one NetworkPolicyPort: nil means "all ports/protocols",
+// matching a rule with no Ports listed. When a TCP port is picked and cfg.Ports has a
+// next-highest entry, the port is promoted to a range via EndPort half the time, to
+// exercise the endPort axis.
+func randomPorts(rng *rand.Rand, cfg Config) []networkingv1.NetworkPolicyPort {
+	if len(cfg.Ports) == 0 || len(cfg.Protocols) == 0 || rng.Intn(3) == 0 {
+		return nil
+	}
+	protocol := cfg.Protocols[rng.Intn(len(cfg.Protocols))]
+	port := cfg.Ports[rng.Intn(len(cfg.Ports))]
+	networkPort := networkingv1.NetworkPolicyPort{
+		Protocol: &protocol,
+		Port:     &intstr.IntOrString{Type: intstr.Int, IntVal: port},
+	}
+	if protocol == v1.ProtocolTCP && rng.Intn(2) == 0 {
+		if end, ok := nextPort(cfg.Ports, port); ok {
+			networkPort.EndPort = &end
+		}
+	}
+	return []networkingv1.NetworkPolicyPort{networkPort}
+}
+
+// nextPort returns the smallest entry of ports that is strictly greater than port, so
+// randomPorts can build a valid [port, end] range from cfg.Ports.
+func nextPort(ports []int32, port int32) (int32, bool) {
+	sorted := append([]int32(nil), ports...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for _, candidate := range sorted {
+		if candidate > port {
+			return candidate, true
+		}
+	}
+	return 0, false
+}
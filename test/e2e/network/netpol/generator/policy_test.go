@@ -0,0 +1,70 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"math/rand"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func testConfig() Config {
+	return Config{
+		PodLabels:  []string{"a", "b", "c"},
+		Namespaces: []string{"x", "y", "z"},
+		Ports:      []int32{80, 81, 82},
+		Protocols:  []v1.Protocol{v1.ProtocolTCP, v1.ProtocolUDP, v1.ProtocolSCTP},
+	}
+}
+
+func TestGeneratePolicyProducesAtLeastOneDirection(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	topo := testTopology()
+	cfg := testConfig()
+
+	for i := 0; i < 100; i++ {
+		policy := GeneratePolicy(rng, "fuzz", "x", topo, cfg)
+		if policy.Namespace != "x" {
+			t.Fatalf("iteration %d: expected policy in namespace x, got %q", i, policy.Namespace)
+		}
+		if policy.Spec.Ingress == nil && policy.Spec.Egress == nil {
+			t.Fatalf("iteration %d: expected at least one of Ingress/Egress to be set", i)
+		}
+	}
+}
+
+func TestGeneratePolicyIsDeterministicForASeed(t *testing.T) {
+	topo := testTopology()
+	cfg := testConfig()
+
+	first := GeneratePolicy(rand.New(rand.NewSource(42)), "fuzz-0", "x", topo, cfg)
+	second := GeneratePolicy(rand.New(rand.NewSource(42)), "fuzz-0", "x", topo, cfg)
+
+	if len(first.Spec.Ingress) != len(second.Spec.Ingress) || len(first.Spec.Egress) != len(second.Spec.Egress) {
+		t.Fatalf("expected the same seed to reproduce the same policy shape, got %+v vs %+v", first.Spec, second.Spec)
+	}
+}
+
+func TestNextPort(t *testing.T) {
+	if end, ok := nextPort([]int32{80, 82, 81}, 80); !ok || end != 81 {
+		t.Errorf("expected next port after 80 to be 81, got %d (ok=%v)", end, ok)
+	}
+	if _, ok := nextPort([]int32{80}, 80); ok {
+		t.Errorf("expected no next port when 80 is the only entry")
+	}
+}
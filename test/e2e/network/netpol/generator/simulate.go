@@ -0,0 +1,224 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"net"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Simulate analytically computes the Reachability truth table policies implies for
+// port/protocol, by evaluating Kubernetes' NetworkPolicy semantics directly: a pod is
+// isolated for a PolicyType only if some policy selecting it (in its own namespace)
+// lists that type, or leaves PolicyTypes unset and has a non-nil Ingress/Egress field
+// (mirroring netpol's OmitPolicyTypes inference); an isolated pod's traffic is allowed
+// only if some selecting policy's rule has a peer and port matching the other side. A
+// connection succeeds only if both the source's egress-side and the destination's
+// ingress-side evaluations allow it, same as two independent policies stacking. The
+// result is keyed by Pod.PodString() on both axes, matching netpol.PodString's format.
+func Simulate(topo Topology, policies []*networkingv1.NetworkPolicy, port int32, protocol v1.Protocol) map[string]map[string]bool {
+	result := make(map[string]map[string]bool, len(topo.Pods))
+	for _, from := range topo.Pods {
+		row := make(map[string]bool, len(topo.Pods))
+		result[from.PodString()] = row
+		for _, to := range topo.Pods {
+			if from.PodString() == to.PodString() {
+				row[to.PodString()] = true // netpol ignores loopback
+				continue
+			}
+			egressOK := !isIsolated(topo, policies, from, networkingv1.PolicyTypeEgress) ||
+				anyRuleAllows(topo, policies, from, to, port, protocol, networkingv1.PolicyTypeEgress)
+			ingressOK := !isIsolated(topo, policies, to, networkingv1.PolicyTypeIngress) ||
+				anyRuleAllows(topo, policies, to, from, port, protocol, networkingv1.PolicyTypeIngress)
+			row[to.PodString()] = egressOK && ingressOK
+		}
+	}
+	return result
+}
+
+// isIsolated reports whether some policy selecting pod (in pod's own namespace) marks
+// it as governing policyType.
+func isIsolated(topo Topology, policies []*networkingv1.NetworkPolicy, pod Pod, policyType networkingv1.PolicyType) bool {
+	for _, policy := range policies {
+		if selectsPod(topo, policy, pod) && hasPolicyType(policy, policyType) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPolicyType reports whether policy governs policyType: its explicit PolicyTypes
+// list if non-empty, otherwise whether the corresponding Ingress/Egress field is set.
+func hasPolicyType(policy *networkingv1.NetworkPolicy, policyType networkingv1.PolicyType) bool {
+	if len(policy.Spec.PolicyTypes) > 0 {
+		for _, t := range policy.Spec.PolicyTypes {
+			if t == policyType {
+				return true
+			}
+		}
+		return false
+	}
+	switch policyType {
+	case networkingv1.PolicyTypeIngress:
+		return policy.Spec.Ingress != nil
+	case networkingv1.PolicyTypeEgress:
+		return policy.Spec.Egress != nil
+	default:
+		return false
+	}
+}
+
+// anyRuleAllows reports whether some policy selecting target (governing policyType)
+// has a rule whose peers match peer and whose ports match port/protocol. For Egress,
+// target is the source pod and peer is the destination; for Ingress it's reversed.
+func anyRuleAllows(topo Topology, policies []*networkingv1.NetworkPolicy, target, peer Pod, port int32, protocol v1.Protocol, policyType networkingv1.PolicyType) bool {
+	for _, policy := range policies {
+		if !selectsPod(topo, policy, target) || !hasPolicyType(policy, policyType) {
+			continue
+		}
+		switch policyType {
+		case networkingv1.PolicyTypeEgress:
+			for _, rule := range policy.Spec.Egress {
+				if rulePeersMatch(topo, policy.Namespace, rule.To, peer) && rulePortsMatch(rule.Ports, port, protocol) {
+					return true
+				}
+			}
+		case networkingv1.PolicyTypeIngress:
+			for _, rule := range policy.Spec.Ingress {
+				if rulePeersMatch(topo, policy.Namespace, rule.From, peer) && rulePortsMatch(rule.Ports, port, protocol) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// selectsPod reports whether policy's PodSelector, evaluated against policy's own
+// namespace, selects pod.
+func selectsPod(topo Topology, policy *networkingv1.NetworkPolicy, pod Pod) bool {
+	if policy.Namespace != pod.Namespace {
+		return false
+	}
+	return matchesSelector(pod.Labels, &policy.Spec.PodSelector)
+}
+
+// matchesSelector reports whether objLabels is matched by selector, handling both
+// MatchLabels and MatchExpressions (In, NotIn, Exists, DoesNotExist) the way the API
+// server's label selector evaluation does. A nil selector matches everything.
+func matchesSelector(objLabels map[string]string, selector *metav1.LabelSelector) bool {
+	if selector == nil {
+		return true
+	}
+	parsed, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+	return parsed.Matches(labels.Set(objLabels))
+}
+
+// rulePeersMatch reports whether peers (a rule's From or To) matches candidate. No
+// peers listed means "match anyone", mirroring a real NetworkPolicyIngressRule/
+// NetworkPolicyEgressRule with an empty peer list.
+func rulePeersMatch(topo Topology, policyNamespace string, peers []networkingv1.NetworkPolicyPeer, candidate Pod) bool {
+	if len(peers) == 0 {
+		return true
+	}
+	for _, peer := range peers {
+		if peerMatches(topo, policyNamespace, peer, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerMatches reports whether a single NetworkPolicyPeer matches candidate: ipBlock is
+// evaluated against candidate's address; otherwise PodSelector and NamespaceSelector
+// conjoin the same way the API does (PodSelector alone is scoped to policyNamespace,
+// NamespaceSelector alone spans every namespace, both together select a specific
+// namespace's matching pods).
+func peerMatches(topo Topology, policyNamespace string, peer networkingv1.NetworkPolicyPeer, candidate Pod) bool {
+	if peer.IPBlock != nil {
+		return ipBlockMatches(peer.IPBlock, candidate.IP)
+	}
+	switch {
+	case peer.NamespaceSelector != nil && peer.PodSelector != nil:
+		return matchesSelector(topo.NamespaceLabels[candidate.Namespace], peer.NamespaceSelector) &&
+			matchesSelector(candidate.Labels, peer.PodSelector)
+	case peer.NamespaceSelector != nil:
+		return matchesSelector(topo.NamespaceLabels[candidate.Namespace], peer.NamespaceSelector)
+	case peer.PodSelector != nil:
+		return candidate.Namespace == policyNamespace && matchesSelector(candidate.Labels, peer.PodSelector)
+	default:
+		return false
+	}
+}
+
+// ipBlockMatches reports whether candidateIP falls within block.CIDR and outside
+// every one of block.Except.
+func ipBlockMatches(block *networkingv1.IPBlock, candidateIP string) bool {
+	ip := net.ParseIP(candidateIP)
+	if ip == nil {
+		return false
+	}
+	_, cidr, err := net.ParseCIDR(block.CIDR)
+	if err != nil || !cidr.Contains(ip) {
+		return false
+	}
+	for _, except := range block.Except {
+		if _, exceptCIDR, err := net.ParseCIDR(except); err == nil && exceptCIDR.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// rulePortsMatch reports whether port/protocol is covered by ports. No ports listed
+// means "all ports and protocols". Named ports aren't resolved here since the fuzzer
+// never generates them (see randomPorts); a named NetworkPolicyPort is simply not a
+// match.
+func rulePortsMatch(ports []networkingv1.NetworkPolicyPort, port int32, protocol v1.Protocol) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	for _, p := range ports {
+		if p.Protocol != nil && *p.Protocol != protocol {
+			continue
+		}
+		if p.Port == nil {
+			return true
+		}
+		if p.Port.Type == intstr.String {
+			continue
+		}
+		if p.EndPort != nil {
+			if port >= p.Port.IntVal && port <= *p.EndPort {
+				return true
+			}
+			continue
+		}
+		if port == p.Port.IntVal {
+			return true
+		}
+	}
+	return false
+}
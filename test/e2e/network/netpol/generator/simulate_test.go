@@ -0,0 +1,130 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func testTopology() Topology {
+	return Topology{
+		Pods: []Pod{
+			{Namespace: "x", Name: "a", Labels: map[string]string{"pod": "a"}, IP: "10.0.0.1"},
+			{Namespace: "x", Name: "b", Labels: map[string]string{"pod": "b"}, IP: "10.0.0.2"},
+			{Namespace: "y", Name: "a", Labels: map[string]string{"pod": "a"}, IP: "10.0.1.1"},
+		},
+		NamespaceLabels: map[string]map[string]string{
+			"x": {namespaceLabelKey: "x"},
+			"y": {namespaceLabelKey: "y"},
+		},
+	}
+}
+
+func TestSimulateDenyIngress(t *testing.T) {
+	topo := testTopology()
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "deny-ingress", Namespace: "x"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+
+	result := Simulate(topo, []*networkingv1.NetworkPolicy{policy}, 80, v1.ProtocolTCP)
+
+	if result["y/a"]["x/a"] {
+		t.Errorf("expected y/a -> x/a to be blocked by default-deny-ingress on x")
+	}
+	if result["x/a"]["x/b"] {
+		t.Errorf("expected x/a -> x/b to be blocked by default-deny-ingress on x/b")
+	}
+	if !result["x/a"]["y/a"] {
+		t.Errorf("expected x/a -> y/a (egress out of x) to remain allowed")
+	}
+}
+
+func TestSimulateOmittedPolicyTypesInfersFromEgressOnly(t *testing.T) {
+	topo := testTopology()
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "deny-egress-no-types", Namespace: "x"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pod": "a"}},
+			Egress:      []networkingv1.NetworkPolicyEgressRule{},
+		},
+	}
+
+	result := Simulate(topo, []*networkingv1.NetworkPolicy{policy}, 80, v1.ProtocolTCP)
+
+	if result["x/a"]["y/a"] {
+		t.Errorf("expected x/a's egress to be blocked even though PolicyTypes was omitted")
+	}
+	if !result["y/a"]["x/a"] {
+		t.Errorf("expected x/a's ingress to remain open since PolicyTypes was omitted and Ingress was never set")
+	}
+}
+
+func TestSimulateIPBlockWithExcept(t *testing.T) {
+	topo := testTopology()
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "allow-cidr-except", Namespace: "x"},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"pod": "a"}},
+			Egress: []networkingv1.NetworkPolicyEgressRule{{
+				To: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{
+					CIDR:   "10.0.0.0/16",
+					Except: []string{"10.0.0.2/32"},
+				}}},
+			}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+		},
+	}
+
+	result := Simulate(topo, []*networkingv1.NetworkPolicy{policy}, 80, v1.ProtocolTCP)
+
+	if !result["x/a"]["y/a"] {
+		t.Errorf("expected x/a -> y/a to be allowed: y/a's IP is in the CIDR and not excepted")
+	}
+	if result["x/a"]["x/b"] {
+		t.Errorf("expected x/a -> x/b to be blocked: x/b's IP is carved out by Except")
+	}
+}
+
+func TestRulePortsMatchEndPortRange(t *testing.T) {
+	tcp := v1.ProtocolTCP
+	end := int32(82)
+	ports := []networkingv1.NetworkPolicyPort{{
+		Protocol: &tcp,
+		Port:     &intstr.IntOrString{Type: intstr.Int, IntVal: 80},
+		EndPort:  &end,
+	}}
+
+	if rulePortsMatch(ports, 79, v1.ProtocolTCP) {
+		t.Errorf("expected port 79 to fall outside the 80-82 range")
+	}
+	if !rulePortsMatch(ports, 81, v1.ProtocolTCP) {
+		t.Errorf("expected port 81 to fall inside the 80-82 range")
+	}
+	if rulePortsMatch(ports, 81, v1.ProtocolUDP) {
+		t.Errorf("expected a TCP-only port rule not to match UDP")
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package generator
+
+// Pod describes one pod the fuzzer knows about: its identity, the labels it was
+// created with, and its live IP, which ipBlock peers are evaluated against.
+type Pod struct {
+	Namespace string
+	Name      string
+	Labels    map[string]string
+	IP        string
+}
+
+// PodString renders p as "namespace/name", matching netpol.PodString's format so
+// callers can key a Reachability truth table with Simulate's result directly.
+func (p Pod) PodString() string {
+	return p.Namespace + "/" + p.Name
+}
+
+// Topology is the fixed pod/namespace universe GeneratePolicy draws peers from and
+// Simulate evaluates generated policies over. It mirrors whatever x/y/z namespace
+// model a test built via initializeResources, plus every pod's live IP so ipBlock
+// peers can be simulated against real addresses.
+type Topology struct {
+	Pods            []Pod
+	NamespaceLabels map[string]map[string]string
+}
@@ -0,0 +1,586 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netpol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	e2eskipper "k8s.io/kubernetes/test/e2e/framework/skipper"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+	utilnet "k8s.io/utils/net"
+)
+
+// kubeManager owns the namespaces/pods/services the test created, and knows how to
+// turn a Model into real cluster objects, and how to validate a TestCase's expected
+// Reachability against real connectivity.
+type kubeManager struct {
+	framework      *framework.Framework
+	clientSet      clientset.Interface
+	dnsDomain      string
+	namespaceNames []string
+	model          *Model
+}
+
+// newKubeManager creates a kubeManager bound to f's clientset.
+func newKubeManager(f *framework.Framework, dnsDomain string) *kubeManager {
+	return &kubeManager{
+		framework: f,
+		clientSet: f.ClientSet,
+		dnsDomain: dnsDomain,
+	}
+}
+
+// NamespaceNames returns the namespace names the model was initialized with, in the
+// same x/y/z order getNamespaceNames produced them.
+func (k *kubeManager) NamespaceNames() []string {
+	return k.namespaceNames
+}
+
+// AllPodStrings returns the PodString for every pod the model describes.
+func (k *kubeManager) AllPodStrings() []PodString {
+	return k.model.AllPodStrings()
+}
+
+// NamespaceLabels fetches the current, live labels of every namespace under test, for
+// resolving SameLabels peers at validation time (i.e. when a test builds its expected
+// Reachability, which may be after namespace labels were changed via AddNamespaceLabel
+// or DeleteNamespaceLabel).
+func (k *kubeManager) NamespaceLabels(ctx context.Context) (map[string]map[string]string, error) {
+	nsLabels := make(map[string]map[string]string, len(k.namespaceNames))
+	for _, nsName := range k.namespaceNames {
+		ns, err := k.clientSet.CoreV1().Namespaces().Get(ctx, nsName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("unable to get namespace %s: %w", nsName, err)
+		}
+		nsLabels[nsName] = ns.Labels
+	}
+	return nsLabels, nil
+}
+
+// initializeClusterFromModel ensures a namespace, a pod, and a matching service exist
+// for everything model describes, then waits for every pod to be running.
+func (k *kubeManager) initializeClusterFromModel(ctx context.Context, model *Model) error {
+	k.model = model
+	for _, ns := range model.Namespaces {
+		k.namespaceNames = append(k.namespaceNames, ns.Name)
+
+		if _, err := k.clientSet.CoreV1().Namespaces().Get(ctx, ns.Name, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+			if _, err := k.clientSet.CoreV1().Namespaces().Create(ctx, &v1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: ns.Name, Labels: ns.Labels},
+			}, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("unable to create namespace %s: %w", ns.Name, err)
+			}
+		} else if err != nil {
+			return fmt.Errorf("unable to get namespace %s: %w", ns.Name, err)
+		} else if err := k.setNamespaceLabels(ctx, ns.Name, ns.Labels); err != nil {
+			return err
+		}
+
+		for _, pod := range ns.Pods {
+			if err := k.createPod(ctx, pod, model.Ports, model.Protocols); err != nil {
+				return err
+			}
+			if err := k.createService(ctx, pod, model.Ports, model.Protocols); err != nil {
+				return err
+			}
+		}
+	}
+	for _, pod := range model.AllPods() {
+		if err := e2epod.WaitForPodRunningInNamespace(ctx, k.clientSet, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}}); err != nil {
+			return fmt.Errorf("pod %s/%s did not become ready: %w", pod.Namespace, pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// containerName returns the name (and, for named ports, the port name) used for the
+// container/port that serves protocol traffic on port, following the "serve-<port>-
+// <protocol>" naming convention some of the tests in this suite reference directly via
+// a named NetworkPolicyPort.
+func containerName(port int32, protocol v1.Protocol) string {
+	return fmt.Sprintf("serve-%d-%s", port, strings.ToLower(string(protocol)))
+}
+
+func (k *kubeManager) createPod(ctx context.Context, pod *Pod, ports []int32, protocols []v1.Protocol) error {
+	var containers []v1.Container
+	for _, port := range ports {
+		for _, protocol := range protocols {
+			name := containerName(port, protocol)
+			args := []string{"netexec"}
+			switch protocol {
+			case v1.ProtocolTCP:
+				args = append(args, fmt.Sprintf("--http-port=%d", port))
+			case v1.ProtocolUDP:
+				args = append(args, fmt.Sprintf("--udp-port=%d", port))
+			case v1.ProtocolSCTP:
+				args = append(args, fmt.Sprintf("--sctp-port=%d", port))
+			}
+			containers = append(containers, v1.Container{
+				Name:            name,
+				Image:           imageutils.GetE2EImage(imageutils.Agnhost),
+				Args:            args,
+				Ports:           []v1.ContainerPort{{ContainerPort: port, Name: name, Protocol: protocol}},
+				ImagePullPolicy: v1.PullIfNotPresent,
+			})
+		}
+	}
+
+	_, err := k.clientSet.CoreV1().Pods(pod.Namespace).Create(ctx, &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace, Labels: pod.Labels},
+		Spec:       v1.PodSpec{Containers: containers},
+	}, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func (k *kubeManager) createService(ctx context.Context, pod *Pod, ports []int32, protocols []v1.Protocol) error {
+	var svcPorts []v1.ServicePort
+	for _, port := range ports {
+		for _, protocol := range protocols {
+			name := containerName(port, protocol)
+			svcPorts = append(svcPorts, v1.ServicePort{Name: name, Port: port, TargetPort: intstr.FromInt32(port), Protocol: protocol})
+		}
+	}
+	_, err := k.clientSet.CoreV1().Services(pod.Namespace).Create(ctx, &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		Spec: v1.ServiceSpec{
+			Selector: pod.Labels,
+			Ports:    svcPorts,
+		},
+	}, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+// waitForHTTPServers waits for every pod's HTTP server(s) to be reachable from the
+// test framework's perspective before any policy is created, so that a later failed
+// probe can be blamed on a policy rather than on a server that was never ready.
+func waitForHTTPServers(k *kubeManager, model *Model) error {
+	const maxWait = 3 * time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), maxWait)
+	defer cancel()
+
+	reachability := NewReachability(model.AllPodStrings(), true)
+	for _, port := range model.Ports {
+		if err := validateOrErr(ctx, k, &TestCase{ToPort: int(port), Protocol: v1.ProtocolTCP, Reachability: reachability}); err != nil {
+			return fmt.Errorf("unable to confirm all pods' HTTP servers are reachable before testing begins: %w", err)
+		}
+	}
+	return nil
+}
+
+// NodeProbeNamespace is the namespace EnsureNodeProbePods creates its host-network
+// probe pods in, so that a node's probe pod's PodString reads "node/<nodeName>" (see
+// Peer.Node), the stable name the node-selector peer tests key their expectations on.
+const NodeProbeNamespace = "node"
+
+// EnsureNodeProbePods creates, if not already present, one host-network pod per cluster
+// node, pinned to that node via Spec.NodeName and serving ports/protocols the same way
+// kubeManager.createPod does for ordinary model pods, then folds them into k8s's model as
+// a NodeProbeNamespace namespace. This lets ValidateOrFail/Reachability treat an actual
+// Node's IP as a probe-able peer - named "node/<nodeName>" - the same way it treats
+// ordinary namespace/pod peers, which is what tests for NetworkPolicy rules whose peers
+// select Nodes (host-network traffic) need to assert against.
+func (k *kubeManager) EnsureNodeProbePods(ctx context.Context, ports []int32, protocols []v1.Protocol) ([]*Pod, error) {
+	if _, err := k.clientSet.CoreV1().Namespaces().Get(ctx, NodeProbeNamespace, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+		if _, err := k.clientSet.CoreV1().Namespaces().Create(ctx, &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: NodeProbeNamespace},
+		}, metav1.CreateOptions{}); err != nil {
+			return nil, fmt.Errorf("unable to create namespace %s: %w", NodeProbeNamespace, err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to get namespace %s: %w", NodeProbeNamespace, err)
+	}
+
+	nodes, err := k.clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	var pods []*Pod
+	for _, node := range nodes.Items {
+		pod := &Pod{Namespace: NodeProbeNamespace, Name: node.Name, Labels: map[string]string{"pod": node.Name}}
+		if err := k.createNodeProbePod(ctx, pod, node.Name, ports, protocols); err != nil {
+			return nil, err
+		}
+		pods = append(pods, pod)
+	}
+	for _, pod := range pods {
+		if err := e2epod.WaitForPodRunningInNamespace(ctx, k.clientSet, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}}); err != nil {
+			return nil, fmt.Errorf("node probe pod %s/%s did not become ready: %w", pod.Namespace, pod.Name, err)
+		}
+	}
+
+	k.model.Namespaces = append(k.model.Namespaces, &Namespace{Name: NodeProbeNamespace, Pods: pods})
+	return pods, nil
+}
+
+func (k *kubeManager) createNodeProbePod(ctx context.Context, pod *Pod, nodeName string, ports []int32, protocols []v1.Protocol) error {
+	var containers []v1.Container
+	for _, port := range ports {
+		for _, protocol := range protocols {
+			name := containerName(port, protocol)
+			args := []string{"netexec"}
+			switch protocol {
+			case v1.ProtocolTCP:
+				args = append(args, fmt.Sprintf("--http-port=%d", port))
+			case v1.ProtocolUDP:
+				args = append(args, fmt.Sprintf("--udp-port=%d", port))
+			case v1.ProtocolSCTP:
+				args = append(args, fmt.Sprintf("--sctp-port=%d", port))
+			}
+			containers = append(containers, v1.Container{
+				Name:            name,
+				Image:           imageutils.GetE2EImage(imageutils.Agnhost),
+				Args:            args,
+				ImagePullPolicy: v1.PullIfNotPresent,
+			})
+		}
+	}
+
+	_, err := k.clientSet.CoreV1().Pods(pod.Namespace).Create(ctx, &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace, Labels: pod.Labels},
+		Spec: v1.PodSpec{
+			Containers:  containers,
+			HostNetwork: true,
+			NodeName:    nodeName,
+			DNSPolicy:   v1.DNSClusterFirstWithHostNet,
+			Tolerations: controlPlaneTolerations(),
+		},
+	}, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+// controlPlaneTolerations lets a node probe pod schedule onto a tainted control-plane
+// node, since Spec.NodeName bypasses normal scheduling but not taint enforcement.
+func controlPlaneTolerations() []v1.Toleration {
+	return []v1.Toleration{
+		{Key: "node-role.kubernetes.io/control-plane", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+		{Key: "node-role.kubernetes.io/master", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+	}
+}
+
+// SetSpecEgressToNodeSelector sets a single egress rule, for ports, whose peers are
+// every current Node matching nodeSelector - one ipBlock entry per matching node's
+// internal IP, computed live (core NetworkPolicy has no Node selector of its own; see
+// nodeIPBlockPeersMatching).
+func SetSpecEgressToNodeSelector(ctx context.Context, k8s *kubeManager, nodeSelector labels.Selector, ports ...networkingv1.NetworkPolicyPort) NetworkPolicySpecMutator {
+	rule := networkingv1.NetworkPolicyEgressRule{
+		To:    nodeIPBlockPeersMatching(ctx, k8s, nodeSelector),
+		Ports: ports,
+	}
+	return SetSpecEgressRules(rule)
+}
+
+// SetSpecIngressFromNodeSelector is SetSpecEgressToNodeSelector's ingress counterpart.
+func SetSpecIngressFromNodeSelector(ctx context.Context, k8s *kubeManager, nodeSelector labels.Selector, ports ...networkingv1.NetworkPolicyPort) NetworkPolicySpecMutator {
+	rule := networkingv1.NetworkPolicyIngressRule{
+		From:  nodeIPBlockPeersMatching(ctx, k8s, nodeSelector),
+		Ports: ports,
+	}
+	return SetSpecIngressRules(rule)
+}
+
+// AllowDNSAndNodeEgress builds (but does not create) a NetworkPolicy selecting every pod
+// that default-denies egress but carves out the two allow rules a practical
+// namespace-isolating policy typically needs to remain functional: DNS lookups
+// (UDP/TCP 53) to kube-system's kube-dns pods, and traffic to every node's IP,
+// discovered from the live node list, via ipBlock. Ingress is left untouched. It sets
+// no namespace; the policy applies to the namespace it is created in.
+func AllowDNSAndNodeEgress(ctx context.Context, k8s *kubeManager) *networkingv1.NetworkPolicy {
+	return GenNetworkPolicyWithNameAndPodSelector("allow-dns-and-node-egress", metav1.LabelSelector{}, SetSpecEgressRulesWithDNSAndNode(ctx, k8s))
+}
+
+// SetSpecEgressRulesWithDNSAndNode is SetSpecEgressRules plus the two companion rules
+// AllowDNSAndNodeEgress relies on: DNS lookups (UDP/TCP 53) to kube-system's kube-dns
+// pods, and traffic to every node's IP via ipBlock. Tests that need a user-authored
+// egress-deny policy to keep DNS and node-local connectivity working (the way a real
+// cluster's default-deny egress policy would be deployed alongside such allow rules)
+// should use this instead of SetSpecEgressRules.
+func SetSpecEgressRulesWithDNSAndNode(ctx context.Context, k8s *kubeManager, rules ...networkingv1.NetworkPolicyEgressRule) NetworkPolicySpecMutator {
+	essentials := append([]networkingv1.NetworkPolicyEgressRule{}, rules...)
+	essentials = append(essentials, dnsEgressRule(), networkingv1.NetworkPolicyEgressRule{To: nodeIPBlockPeersMatching(ctx, k8s, labels.Everything())})
+	return SetSpecEgressRules(essentials...)
+}
+
+// dnsEgressRule returns an egress rule permitting DNS lookups (UDP/TCP 53) to
+// kube-system's kube-dns pods.
+func dnsEgressRule() networkingv1.NetworkPolicyEgressRule {
+	return networkingv1.NetworkPolicyEgressRule{
+		To: []networkingv1.NetworkPolicyPeer{{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{namespaceLabelKey: metav1.NamespaceSystem}},
+			PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": "kube-dns"}},
+		}},
+		Ports: []networkingv1.NetworkPolicyPort{
+			{Protocol: &protocolUDP, Port: &intstr.IntOrString{IntVal: 53}},
+			{Protocol: &protocolTCP, Port: &intstr.IntOrString{IntVal: 53}},
+		},
+	}
+}
+
+// kubeDNSServiceClusterIP returns kube-system's kube-dns Service ClusterIP, skipping the
+// calling spec (rather than failing it) if the cluster has no Service by that name,
+// since not every cluster names its DNS add-on's Service "kube-dns".
+func kubeDNSServiceClusterIP(ctx context.Context, k8s *kubeManager) string {
+	svc, err := k8s.clientSet.CoreV1().Services(metav1.NamespaceSystem).Get(ctx, "kube-dns", metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		e2eskipper.Skipf("no kube-dns Service found in namespace %s", metav1.NamespaceSystem)
+	}
+	framework.ExpectNoError(err, "getting kube-dns service")
+	if svc.Spec.ClusterIP == "" || svc.Spec.ClusterIP == v1.ClusterIPNone {
+		e2eskipper.Skipf("kube-dns Service has no usable ClusterIP")
+	}
+	return svc.Spec.ClusterIP
+}
+
+// nodeIPBlockPeersMatching lists the cluster's nodes and returns one ipBlock peer per
+// internal IP of every node whose labels satisfy nodeSelector, each scoped to that
+// single address. Pass labels.Everything() to match every node, the way
+// SetSpecEgressRulesWithDNSAndNode's node-egress essential does.
+func nodeIPBlockPeersMatching(ctx context.Context, k8s *kubeManager, nodeSelector labels.Selector) []networkingv1.NetworkPolicyPeer {
+	nodes, err := k8s.clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	framework.ExpectNoError(err, "listing nodes to build node-egress ipBlock")
+
+	var peers []networkingv1.NetworkPolicyPeer
+	for _, node := range nodes.Items {
+		if !nodeSelector.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		for _, addr := range node.Status.Addresses {
+			if addr.Type != v1.NodeInternalIP {
+				continue
+			}
+			hostMask := 32
+			if utilnet.IsIPv6String(addr.Address) {
+				hostMask = 128
+			}
+			peers = append(peers, networkingv1.NetworkPolicyPeer{
+				IPBlock: &networkingv1.IPBlock{CIDR: fmt.Sprintf("%s/%d", addr.Address, hostMask)},
+			})
+		}
+	}
+	return peers
+}
+
+// CreatePolicy creates policy in namespace ns.
+func CreatePolicy(ctx context.Context, k8s *kubeManager, policy *networkingv1.NetworkPolicy, ns string) {
+	if *compactPolicies {
+		policy = CompactPolicy(policy)
+	}
+	_, err := k8s.clientSet.NetworkingV1().NetworkPolicies(ns).Create(ctx, policy, metav1.CreateOptions{})
+	framework.ExpectNoError(err, "creating network policy %s/%s", ns, policy.Name)
+}
+
+// UpdatePolicy updates an existing policy in namespace ns.
+func UpdatePolicy(ctx context.Context, k8s *kubeManager, policy *networkingv1.NetworkPolicy, ns string) {
+	if *compactPolicies {
+		policy = CompactPolicy(policy)
+	}
+	existing, err := k8s.clientSet.NetworkingV1().NetworkPolicies(ns).Get(ctx, policy.Name, metav1.GetOptions{})
+	framework.ExpectNoError(err, "getting network policy %s/%s before update", ns, policy.Name)
+	policy.ResourceVersion = existing.ResourceVersion
+	_, err = k8s.clientSet.NetworkingV1().NetworkPolicies(ns).Update(ctx, policy, metav1.UpdateOptions{})
+	framework.ExpectNoError(err, "updating network policy %s/%s", ns, policy.Name)
+}
+
+// AddNamespaceLabel adds key=value to namespace ns's labels.
+func AddNamespaceLabel(ctx context.Context, k8s *kubeManager, ns, key, value string) {
+	existing, err := k8s.clientSet.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+	framework.ExpectNoError(err, "getting namespace %s", ns)
+	labels := cloneLabels(existing.Labels)
+	labels[key] = value
+	framework.ExpectNoError(k8s.setNamespaceLabels(ctx, ns, labels), "adding label %s=%s to namespace %s", key, value, ns)
+}
+
+// DeleteNamespaceLabel removes key from namespace ns's labels.
+func DeleteNamespaceLabel(ctx context.Context, k8s *kubeManager, ns, key string) {
+	existing, err := k8s.clientSet.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+	framework.ExpectNoError(err, "getting namespace %s", ns)
+	labels := cloneLabels(existing.Labels)
+	delete(labels, key)
+	framework.ExpectNoError(k8s.setNamespaceLabels(ctx, ns, labels), "deleting label %s from namespace %s", key, ns)
+}
+
+func (k *kubeManager) setNamespaceLabels(ctx context.Context, ns string, labels map[string]string) error {
+	existing, err := k.clientSet.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get namespace %s: %w", ns, err)
+	}
+	existing.Labels = labels
+	_, err = k.clientSet.CoreV1().Namespaces().Update(ctx, existing, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to update namespace %s labels: %w", ns, err)
+	}
+	return nil
+}
+
+// AddPodLabels adds labels to pod name in namespace ns.
+func AddPodLabels(ctx context.Context, k8s *kubeManager, ns, name string, labels map[string]string) {
+	pod, err := k8s.clientSet.CoreV1().Pods(ns).Get(ctx, name, metav1.GetOptions{})
+	framework.ExpectNoError(err, "getting pod %s/%s", ns, name)
+	merged := cloneLabels(pod.Labels)
+	for k, v := range labels {
+		merged[k] = v
+	}
+	pod.Labels = merged
+	_, err = k8s.clientSet.CoreV1().Pods(ns).Update(ctx, pod, metav1.UpdateOptions{})
+	framework.ExpectNoError(err, "updating labels on pod %s/%s", ns, name)
+}
+
+// ResetPodLabels resets pod name in namespace ns back to the single "pod": name label
+// every model pod is created with.
+func ResetPodLabels(ctx context.Context, k8s *kubeManager, ns, name string) {
+	pod, err := k8s.clientSet.CoreV1().Pods(ns).Get(ctx, name, metav1.GetOptions{})
+	framework.ExpectNoError(err, "getting pod %s/%s", ns, name)
+	pod.Labels = map[string]string{"pod": name}
+	_, err = k8s.clientSet.CoreV1().Pods(ns).Update(ctx, pod, metav1.UpdateOptions{})
+	framework.ExpectNoError(err, "resetting labels on pod %s/%s", ns, name)
+}
+
+// ValidateOrFail probes connectivity between every pair of pods the test's
+// Reachability matrix covers, for testCase's port and protocol, and fails the test if
+// any pair's observed connectivity doesn't match what was expected.
+func ValidateOrFail(k8s *kubeManager, testCase *TestCase) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	framework.ExpectNoError(validateOrErr(ctx, k8s, testCase), "validating reachability for port %d/%s", testCase.ToPort, testCase.Protocol)
+}
+
+func validateOrErr(ctx context.Context, k8s *kubeManager, testCase *TestCase) error {
+	for _, port := range portsForTestCase(testCase) {
+		if err := validatePortOrErr(ctx, k8s, testCase.Reachability, port, testCase.Protocol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// portsForTestCase expands a TestCase's ToPort/EndPort into the individual ports
+// validateOrErr should probe: just ToPort if EndPort is unset, or every port from ToPort
+// through EndPort (inclusive) otherwise.
+func portsForTestCase(testCase *TestCase) []int {
+	if testCase.EndPort == 0 {
+		return []int{testCase.ToPort}
+	}
+	ports := make([]int, 0, testCase.EndPort-testCase.ToPort+1)
+	for port := testCase.ToPort; port <= testCase.EndPort; port++ {
+		ports = append(ports, port)
+	}
+	return ports
+}
+
+func validatePortOrErr(ctx context.Context, k8s *kubeManager, reachability *Reachability, port int, protocol v1.Protocol) error {
+	tt, err := reachability.ProbeAll(ctx, k8s, port, protocol, ProberOpts{})
+	if err != nil {
+		return err
+	}
+	if tt.Wrong > 0 {
+		var summary strings.Builder
+		fmt.Fprintf(&summary, "port %d: %d wrong cells\n", port, tt.Wrong)
+		reachability.PrintSummary(&summary)
+		return errors.New(summary.String())
+	}
+	return nil
+}
+
+// podIPsByFamily returns pod's IPv4 and IPv6 addresses, each "" if pod has none of that
+// family, read from its (possibly dual-stack) Status.PodIPs list.
+func podIPsByFamily(pod *v1.Pod) (ipv4, ipv6 string) {
+	for _, podIP := range pod.Status.PodIPs {
+		if utilnet.IsIPv4String(podIP.IP) {
+			ipv4 = podIP.IP
+		} else if utilnet.IsIPv6String(podIP.IP) {
+			ipv6 = podIP.IP
+		}
+	}
+	return ipv4, ipv6
+}
+
+// probeConnectivity execs into fromPod and asks it to dial toPod on port/protocol,
+// matching the connectivity the real traffic a NetworkPolicy would govern.
+func probeConnectivity(ctx context.Context, k8s *kubeManager, fromPod, toPod *Pod, port int, protocol v1.Protocol) (bool, error) {
+	if fromPod == toPod && ignoreLoopback {
+		return true, nil
+	}
+
+	toPodObj, err := k8s.clientSet.CoreV1().Pods(toPod.Namespace).Get(ctx, toPod.Name, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("unable to get destination pod %s/%s: %w", toPod.Namespace, toPod.Name, err)
+	}
+	return probeAddress(k8s, fromPod, port, protocol, toPodObj.Status.PodIP, port, protocol)
+}
+
+// probeAddress execs into fromPod (using the serving container the model created it
+// with for fromPort/fromProtocol) and asks it to dial destAddr:destPort/destProtocol.
+// Unlike probeConnectivity, destAddr isn't resolved from one of the model's own pods,
+// so this is what tests reach for when the destination is something else reachable on
+// the pod's network, like a DNS server or a node.
+func probeAddress(k8s *kubeManager, fromPod *Pod, fromPort int, fromProtocol v1.Protocol, destAddr string, destPort int, destProtocol v1.Protocol) (bool, error) {
+	cmd := []string{"/agnhost", "connect", fmt.Sprintf("%s:%d", destAddr, destPort), "--timeout=2s"}
+	if destProtocol != v1.ProtocolTCP {
+		cmd = append(cmd, fmt.Sprintf("--protocol=%s", strings.ToLower(string(destProtocol))))
+	}
+
+	_, _, execErr := e2epod.ExecCommandInContainerWithFullOutput(k8s.framework, fromPod.Name, containerName(int32(fromPort), fromProtocol), cmd...)
+	return execErr == nil, nil
+}
+
+// skipUnlessProtocolReachable probes baseline (pre-policy) connectivity between the
+// first two pods the model describes, for port/protocol, and skips the calling spec via
+// e2eskipper if it isn't reachable. It exists for protocols like SCTP that depend on a
+// kernel module not every cluster's nodes have loaded: failing to connect there is an
+// environment limitation, not something any NetworkPolicy under test could explain, so
+// runForProtocols uses it to bow out of the unsupported variant instead of reporting a
+// false enforcement failure.
+func skipUnlessProtocolReachable(ctx context.Context, k8s *kubeManager, port int32, protocol v1.Protocol) {
+	pods := k8s.model.AllPods()
+	if len(pods) < 2 {
+		return
+	}
+	connected, err := probeConnectivity(ctx, k8s, pods[0], pods[1], int(port), protocol)
+	if err != nil || !connected {
+		e2eskipper.Skipf("protocol %s does not appear to be usable on this cluster (baseline probe from %s to %s on port %d failed): %v", protocol, pods[0].PodString(), pods[1].PodString(), port, err)
+	}
+}
+
+func cloneLabels(labels map[string]string) map[string]string {
+	clone := make(map[string]string, len(labels))
+	for k, v := range labels {
+		clone[k] = v
+	}
+	return clone
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netpol
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Pod describes one server pod in the model: its identity and the labels it is created
+// with. The ports/protocols it listens on are the Model's Ports and Protocols.
+type Pod struct {
+	Namespace string
+	Name      string
+	Labels    map[string]string
+}
+
+// PodString returns the PodString identifying this pod.
+func (p *Pod) PodString() PodString {
+	return NewPodString(p.Namespace, p.Name)
+}
+
+// Namespace describes one test namespace in the model: its name, the labels it is
+// created with, and the pods that live in it.
+type Namespace struct {
+	Name   string
+	Labels map[string]string
+	Pods   []*Pod
+}
+
+// Model captures the namespace/pod topology that a test wants to exist, plus the
+// ports/protocols every pod's HTTP server should listen on, so that the cluster can be
+// built from it (see kubeManager.initializeClusterFromModel) and so that
+// NewReachability can build a default truth table sized to match it.
+type Model struct {
+	Namespaces []*Namespace
+	Ports      []int32
+	Protocols  []v1.Protocol
+}
+
+// newModelWithPerNamespacePodNames builds a Model for the given namespaces, where
+// podNamesByNamespace lists which pod names (e.g. "a", "b") should exist in each
+// namespace. Every pod is labeled with "pod": <name>, and every namespace is labeled
+// with the well-known namespaceLabelKey so that NamespaceSelector-based policies can
+// select namespaces by name the way a real cluster's namespace admission controller
+// would label them.
+func newModelWithPerNamespacePodNames(namespaces []string, podNamesByNamespace map[string]sets.Set[string], ports []int32, protocols []v1.Protocol) *Model {
+	model := &Model{Ports: ports, Protocols: protocols}
+	for _, nsName := range namespaces {
+		ns := &Namespace{
+			Name:   nsName,
+			Labels: map[string]string{namespaceLabelKey: nsName},
+		}
+		for _, podName := range sets.List(podNamesByNamespace[nsName]) {
+			ns.Pods = append(ns.Pods, &Pod{
+				Namespace: nsName,
+				Name:      podName,
+				Labels:    map[string]string{"pod": podName},
+			})
+		}
+		model.Namespaces = append(model.Namespaces, ns)
+	}
+	return model
+}
+
+// AllPods returns every pod described by the model.
+func (m *Model) AllPods() []*Pod {
+	var pods []*Pod
+	for _, ns := range m.Namespaces {
+		pods = append(pods, ns.Pods...)
+	}
+	return pods
+}
+
+// AllPodStrings returns the PodString for every pod described by the model.
+func (m *Model) AllPodStrings() []PodString {
+	var podStrings []PodString
+	for _, pod := range m.AllPods() {
+		podStrings = append(podStrings, pod.PodString())
+	}
+	return podStrings
+}
+
+// NamespacesByName indexes the model's namespaces by name.
+func (m *Model) NamespacesByName() map[string]*Namespace {
+	byName := make(map[string]*Namespace, len(m.Namespaces))
+	for _, ns := range m.Namespaces {
+		byName[ns.Name] = ns
+	}
+	return byName
+}
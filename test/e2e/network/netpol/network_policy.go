@@ -19,6 +19,7 @@ package netpol
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"net"
 	"strings"
 	"time"
@@ -29,13 +30,16 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 
 	"github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/features"
 	"k8s.io/kubernetes/test/e2e/feature"
 	"k8s.io/kubernetes/test/e2e/framework"
 	e2eskipper "k8s.io/kubernetes/test/e2e/framework/skipper"
 	"k8s.io/kubernetes/test/e2e/network/common"
+	"k8s.io/kubernetes/test/e2e/network/netpol/generator"
 	admissionapi "k8s.io/pod-security-admission/api"
 	utilnet "k8s.io/utils/net"
 )
@@ -104,7 +108,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability.ExpectPeer(&Peer{}, &Peer{Namespace: nsX}, false)
 
 			// Confirm that the real world connectivity matches our matrix
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should support a 'default-deny-all' policy", feature.NetworkPolicy, func(ctx context.Context) {
@@ -123,33 +127,123 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability.ExpectPeer(&Peer{}, &Peer{Namespace: nsX}, false)
 			reachability.ExpectPeer(&Peer{Namespace: nsX}, &Peer{}, false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
-		f.It("should enforce policy to allow traffic from pods within server namespace based on PodSelector", feature.NetworkPolicy, func(ctx context.Context) {
+		f.It("should infer Ingress from Spec.Ingress when PolicyTypes is omitted", feature.NetworkPolicy, func(ctx context.Context) {
 			protocols := []v1.Protocol{protocolTCP}
 			ports := []int32{80}
-			// Policy isolates x/a and only allows ingress from x/b, so we need x/b as the
-			// allowed same-namespace peer, x/c as a same-namespace non-matching pod, and
-			// y/a as a cross-namespace peer that must not be able to reach x/a.
-			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b", "x/c", "y/a")
+			// Policy on x/a has Ingress rules but no PolicyTypes, so we need x/b to show
+			// same-namespace ingress is still blocked and y/a to show cross-namespace
+			// ingress is blocked too, while x/a's own egress must remain unrestricted.
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b", "y/a")
 			nsX, _, _ := getK8sNamespaces(k8s)
 
-			allowedPods := metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"pod": "b",
-				},
-			}
-			ingressRule := networkingv1.NetworkPolicyIngressRule{}
-			ingressRule.From = append(ingressRule.From, networkingv1.NetworkPolicyPeer{PodSelector: &allowedPods})
-			policy := GenNetworkPolicyWithNameAndPodMatchLabel("x-a-allows-x-b", map[string]string{"pod": "a"}, SetSpecIngressRules(ingressRule))
+			policy := GenNetworkPolicyWithNameAndPodMatchLabel("deny-ingress-no-policy-types", map[string]string{"pod": "a"}, SetSpecIngressRules(), OmitPolicyTypes())
+			gomega.Expect(policy.Spec.PolicyTypes).To(gomega.BeEmpty(), "test setup: policy must omit PolicyTypes")
 			CreatePolicy(ctx, k8s, policy, nsX)
 
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
-			reachability.Expect(NewPodString(nsX, "b"), NewPodString(nsX, "a"), true)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
+		})
+
+		f.It("should infer Egress from Spec.Egress when PolicyTypes is omitted", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolTCP}
+			ports := []int32{80}
+			// Policy on x/a has Egress rules but no PolicyTypes, so x/a must lose egress
+			// to both x/b (same namespace) and y/a (cross-namespace), while ingress to
+			// x/a from either remains unrestricted.
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b", "y/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			policy := GenNetworkPolicyWithNameAndPodMatchLabel("deny-egress-no-policy-types", map[string]string{"pod": "a"}, SetSpecEgressRules(), OmitPolicyTypes())
+			gomega.Expect(policy.Spec.PolicyTypes).To(gomega.BeEmpty(), "test setup: policy must omit PolicyTypes")
+			CreatePolicy(ctx, k8s, policy, nsX)
+
+			reachability := NewReachability(k8s.AllPodStrings(), true)
+			reachability.ExpectPeer(&Peer{Namespace: nsX, Pod: "a"}, &Peer{}, false)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
+		})
+
+		f.It("should infer both Ingress and Egress from Spec.Ingress and Spec.Egress when PolicyTypes is omitted", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolTCP}
+			ports := []int32{80}
+			// Policy on x/a has both Ingress and Egress rules but no PolicyTypes, so both
+			// directions must end up enforced: x/a loses ingress from x/b and y/a, and loses
+			// egress to both too.
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b", "y/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			policy := GenNetworkPolicyWithNameAndPodMatchLabel("deny-both-no-policy-types", map[string]string{"pod": "a"}, SetSpecIngressRules(), SetSpecEgressRules(), SetSpecPolicyTypesUnset())
+			gomega.Expect(policy.Spec.PolicyTypes).To(gomega.BeEmpty(), "test setup: policy must omit PolicyTypes")
+			CreatePolicy(ctx, k8s, policy, nsX)
+
+			stored, err := k8s.clientSet.NetworkingV1().NetworkPolicies(nsX).Get(ctx, policy.Name, metav1.GetOptions{})
+			framework.ExpectNoError(err, "fetching stored policy %s/%s", nsX, policy.Name)
+			gomega.Expect(stored.Spec.PolicyTypes).To(gomega.ConsistOf(networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress),
+				"server-side PolicyTypes should be inferred from the presence of both Ingress and Egress rules")
+
+			reachability := NewReachability(k8s.AllPodStrings(), true)
+			reachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
+			reachability.ExpectPeer(&Peer{Namespace: nsX, Pod: "a"}, &Peer{}, false)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
+		})
+
+		f.It("should infer no PolicyTypes when neither Ingress, Egress, nor PolicyTypes is set", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolTCP}
+			ports := []int32{80}
+			// Policy on x/a has no Ingress, no Egress, and no PolicyTypes at all, so under
+			// this framework's PolicyTypes-inference model (Ingress from non-nil Spec.Ingress,
+			// Egress from non-nil Spec.Egress, independently of each other — see
+			// OmitPolicyTypes) neither direction is inferred: the policy governs nothing, so
+			// it must leave x/a's ingress and egress exactly as unrestricted as if the policy
+			// didn't exist.
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b", "y/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			policy := GenNetworkPolicyWithNameAndPodMatchLabel("no-rules-no-policy-types", map[string]string{"pod": "a"}, SetSpecPolicyTypesUnset())
+			gomega.Expect(policy.Spec.PolicyTypes).To(gomega.BeEmpty(), "test setup: policy must omit PolicyTypes")
+			CreatePolicy(ctx, k8s, policy, nsX)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			stored, err := k8s.clientSet.NetworkingV1().NetworkPolicies(nsX).Get(ctx, policy.Name, metav1.GetOptions{})
+			framework.ExpectNoError(err, "fetching stored policy %s/%s", nsX, policy.Name)
+			gomega.Expect(stored.Spec.PolicyTypes).To(gomega.BeEmpty(),
+				"server-side PolicyTypes should stay empty when neither Ingress nor Egress rules are present")
+
+			reachability := NewReachability(k8s.AllPodStrings(), true)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
+		})
+
+		f.It("should enforce policy to allow traffic from pods within server namespace based on PodSelector", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolTCP, protocolUDP, protocolSCTP}
+			ports := []int32{80}
+			// Policy isolates x/a and only allows ingress from x/b, so we need x/b as the
+			// allowed same-namespace peer, x/c as a same-namespace non-matching pod, and
+			// y/a as a cross-namespace peer that must not be able to reach x/a.
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b", "x/c", "y/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			runForProtocols(ctx, k8s, 80, protocols, func(protocol v1.Protocol) {
+				allowedPods := metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"pod": "b",
+					},
+				}
+				ingressRule := networkingv1.NetworkPolicyIngressRule{}
+				ingressRule.From = append(ingressRule.From, networkingv1.NetworkPolicyPeer{PodSelector: &allowedPods})
+				policy := GenNetworkPolicyWithNameAndPodMatchLabel("x-a-allows-x-b", map[string]string{"pod": "a"}, SetSpecIngressRules(ingressRule))
+				CreatePolicy(ctx, k8s, policy, nsX)
+				defer func() {
+					_ = k8s.clientSet.NetworkingV1().NetworkPolicies(nsX).Delete(ctx, policy.Name, metav1.DeleteOptions{})
+				}()
+
+				reachability := NewReachability(k8s.AllPodStrings(), true)
+				reachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
+				reachability.Expect(NewPodString(nsX, "b"), NewPodString(nsX, "a"), true)
+
+				ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: protocol, Reachability: reachability}, OracleModeFromEnv())
+			})
 		})
 
 		f.It("should enforce policy to allow ingress traffic for a target", feature.NetworkPolicy, func(ctx context.Context) {
@@ -176,7 +270,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability.ExpectAllIngress(NewPodString(nsX, "a"), true)
 			reachability.ExpectAllIngress(NewPodString(nsX, "b"), false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce policy to allow ingress traffic from pods in all namespaces", feature.NetworkPolicy, func(ctx context.Context) {
@@ -194,7 +288,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			CreatePolicy(ctx, k8s, policy, nsX)
 
 			reachability := NewReachability(k8s.AllPodStrings(), true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce policy to allow traffic only from a different namespace, based on NamespaceSelector", feature.NetworkPolicy, func(ctx context.Context) {
@@ -216,7 +310,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability.ExpectPeer(&Peer{Namespace: nsX}, &Peer{Namespace: nsX, Pod: "a"}, false)
 			reachability.ExpectPeer(&Peer{Namespace: nsZ}, &Peer{Namespace: nsX, Pod: "a"}, false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce policy based on PodSelector with MatchExpressions", feature.NetworkPolicy, func(ctx context.Context) {
@@ -243,7 +337,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
 			reachability.Expect(NewPodString(nsX, "b"), NewPodString(nsX, "a"), true)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce policy based on NamespaceSelector with MatchExpressions", feature.NetworkPolicy, func(ctx context.Context) {
@@ -272,7 +366,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability.ExpectPeer(&Peer{Namespace: nsX}, &Peer{Namespace: nsX, Pod: "a"}, false)
 			reachability.ExpectPeer(&Peer{Namespace: nsZ}, &Peer{Namespace: nsX, Pod: "a"}, false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce policy based on PodSelector or NamespaceSelector", feature.NetworkPolicy, func(ctx context.Context) {
@@ -306,7 +400,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability.Expect(NewPodString(nsX, "a"), NewPodString(nsX, "a"), false)
 			reachability.Expect(NewPodString(nsX, "c"), NewPodString(nsX, "a"), false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce policy based on PodSelector and NamespaceSelector", feature.NetworkPolicy, func(ctx context.Context) {
@@ -341,7 +435,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability.Expect(NewPodString(nsY, "b"), NewPodString(nsX, "a"), true)
 			reachability.Expect(NewPodString(nsZ, "b"), NewPodString(nsX, "a"), true)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce policy based on Multiple PodSelectors and NamespaceSelectors", feature.NetworkPolicy, func(ctx context.Context) {
@@ -376,7 +470,117 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability.ExpectPeer(&Peer{Namespace: nsX}, &Peer{Namespace: nsX, Pod: "a"}, false)
 			reachability.Expect(NewPodString(nsY, "a"), NewPodString(nsX, "a"), false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
+		})
+
+		f.It("should enforce policy based on a SameLabels namespace peer", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolTCP}
+			ports := []int32{80}
+			// Policy on x/a is meant to allow ingress only from namespaces whose "tier"
+			// label matches x's own "tier" label, regardless of the specific value. Core
+			// NetworkPolicy has no native "peer namespace's label must equal mine" concept
+			// (that's what CNI-specific extensions like Antrea's ClusterNetworkPolicy
+			// SameLabels peer are for), so the policy we create approximates it with a
+			// concrete NamespaceSelector for whatever value we just gave x's own "tier"
+			// label. The Reachability side uses the general SameLabels peer so the
+			// expectation itself is expressed relative to x's namespace rather than
+			// hardcoding "frontend", matching how the test framework would need to behave
+			// against a CNI that does support a real SameLabels peer.
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "y/a", "z/a")
+			nsX, nsY, nsZ := getK8sNamespaces(k8s)
+			ginkgo.DeferCleanup(DeleteNamespaceLabel, k8s, nsX, "tier")
+			ginkgo.DeferCleanup(DeleteNamespaceLabel, k8s, nsY, "tier")
+			ginkgo.DeferCleanup(DeleteNamespaceLabel, k8s, nsZ, "tier")
+			AddNamespaceLabel(ctx, k8s, nsX, "tier", "frontend")
+			AddNamespaceLabel(ctx, k8s, nsY, "tier", "frontend")
+			AddNamespaceLabel(ctx, k8s, nsZ, "tier", "backend")
+
+			allowedNamespaces := &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "frontend"}}
+			ingressRule := networkingv1.NetworkPolicyIngressRule{}
+			ingressRule.From = append(ingressRule.From, networkingv1.NetworkPolicyPeer{NamespaceSelector: allowedNamespaces})
+			policy := GenNetworkPolicyWithNameAndPodMatchLabel("allow-same-tier-namespaces", map[string]string{"pod": "a"}, SetSpecIngressRules(ingressRule))
+			CreatePolicy(ctx, k8s, policy, nsX)
+
+			nsLabels, err := k8s.NamespaceLabels(ctx)
+			framework.ExpectNoError(err, "fetching namespace labels to resolve SameLabels peers")
+
+			reachability := NewReachability(k8s.AllPodStrings(), true)
+			reachability.SetNamespaceLabels(nsLabels)
+			reachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
+			reachability.ExpectPeer(&Peer{SameLabels: []string{"tier"}}, &Peer{Namespace: nsX, Pod: "a"}, true)
+
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
+		})
+
+		f.It("should enforce policy based on a multi-key SameLabels namespace peer, including after a relabel", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolTCP}
+			ports := []int32{80}
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "y/a", "z/a")
+			nsX, nsY, nsZ := getK8sNamespaces(k8s)
+			ginkgo.DeferCleanup(DeleteNamespaceLabel, k8s, nsX, "tenant")
+			ginkgo.DeferCleanup(DeleteNamespaceLabel, k8s, nsY, "tenant")
+			ginkgo.DeferCleanup(DeleteNamespaceLabel, k8s, nsZ, "tenant")
+			ginkgo.DeferCleanup(DeleteNamespaceLabel, k8s, nsX, "region")
+			ginkgo.DeferCleanup(DeleteNamespaceLabel, k8s, nsY, "region")
+			ginkgo.DeferCleanup(DeleteNamespaceLabel, k8s, nsZ, "region")
+			AddNamespaceLabel(ctx, k8s, nsX, "tenant", "acme")
+			AddNamespaceLabel(ctx, k8s, nsX, "region", "east")
+			AddNamespaceLabel(ctx, k8s, nsY, "tenant", "acme")
+			AddNamespaceLabel(ctx, k8s, nsY, "region", "west")
+			AddNamespaceLabel(ctx, k8s, nsZ, "tenant", "acme")
+			AddNamespaceLabel(ctx, k8s, nsZ, "region", "east")
+
+			nsLabels, err := k8s.NamespaceLabels(ctx)
+			framework.ExpectNoError(err, "fetching namespace labels to build the sameLabels policy")
+			policy := GenNetworkPolicyWithNameAndPodMatchLabel("allow-same-tenant-and-region", map[string]string{"pod": "a"},
+				SetSpecIngressRulesWithSameNamespaceLabels(nsLabels[nsX], "tenant", "region"))
+			CreatePolicy(ctx, k8s, policy, nsX)
+
+			reachability := NewReachability(k8s.AllPodStrings(), true)
+			reachability.SetNamespaceLabels(nsLabels)
+			reachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
+			reachability.ExpectPeer(&Peer{SameLabels: []string{"tenant", "region"}}, &Peer{Namespace: nsX, Pod: "a"}, true)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
+
+			ginkgo.By("relabeling nsZ's region so it no longer shares x's equivalence class")
+			AddNamespaceLabel(ctx, k8s, nsZ, "region", "west")
+			nsLabels, err = k8s.NamespaceLabels(ctx)
+			framework.ExpectNoError(err, "re-fetching namespace labels after relabeling nsZ")
+
+			reachability = NewReachability(k8s.AllPodStrings(), true)
+			reachability.SetNamespaceLabels(nsLabels)
+			reachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
+			reachability.ExpectPeer(&Peer{SameLabels: []string{"tenant", "region"}}, &Peer{Namespace: nsX, Pod: "a"}, true)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
+		})
+
+		f.It("should enforce policy based on a SameLabels namespace peer set up via GenNetworkPolicyWithSameNamespaceLabels", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolTCP}
+			ports := []int32{80}
+			// Unlike the two tests above, which label namespaces after initializeResources
+			// and build the policy with the lower-level mutator directly, this exercises the
+			// creation-time path: nsLabels are baked into the namespaces by
+			// initializeResourcesWithNamespaceLabels, and the policy itself comes from the
+			// Gen-wrapper rather than composing SetSpecIngressRulesWithSameNamespaceLabels by
+			// hand.
+			nsLabels := map[string]map[string]string{
+				"x": {"tenant": "acme"},
+				"y": {"tenant": "acme"},
+				"z": {"tenant": "umbrella"},
+			}
+			k8s = initializeResourcesWithNamespaceLabels(ctx, f, protocols, ports, nsLabels, "x/a", "y/a", "z/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			liveNSLabels, err := k8s.NamespaceLabels(ctx)
+			framework.ExpectNoError(err, "fetching namespace labels to build the sameLabels policy")
+			policy := GenNetworkPolicyWithSameNamespaceLabels("allow-same-tenant", map[string]string{"pod": "a"}, liveNSLabels[nsX], "tenant")
+			CreatePolicy(ctx, k8s, policy, nsX)
+
+			reachability := NewReachability(k8s.AllPodStrings(), true)
+			reachability.SetNamespaceLabels(liveNSLabels)
+			reachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
+			reachability.ExpectPeer(&Peer{SameLabels: []string{"tenant"}}, &Peer{Namespace: nsX, Pod: "a"}, true)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce policy based on any PodSelectors", feature.NetworkPolicy, func(ctx context.Context) {
@@ -401,7 +605,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability.Expect(NewPodString(nsX, "b"), NewPodString(nsX, "a"), true)
 			reachability.Expect(NewPodString(nsX, "c"), NewPodString(nsX, "a"), true)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce policy to allow traffic only from a pod in a different namespace based on PodSelector and NamespaceSelector", feature.NetworkPolicy, func(ctx context.Context) {
@@ -432,11 +636,11 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
 			reachability.Expect(NewPodString(nsY, "a"), NewPodString(nsX, "a"), true)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce policy based on Ports", feature.NetworkPolicy, func(ctx context.Context) {
-			protocols := []v1.Protocol{protocolTCP}
+			protocols := []v1.Protocol{protocolTCP, protocolUDP, protocolSCTP}
 			ports := []int32{81}
 			// This test is port-specific: namespace X should allow ingress to x/a on
 			// port 81 from namespace Y only. We include x/b as a same-namespace source
@@ -445,24 +649,129 @@ var _ = common.SIGDescribe("Netpol", func() {
 			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b", "y/a", "y/b", "z/a")
 			nsX, nsY, nsZ := getK8sNamespaces(k8s)
 
-			ginkgo.By("Creating a network allowPort81Policy which only allows allow listed namespaces (y) to connect on exactly one port (81)")
+			runForProtocols(ctx, k8s, 81, protocols, func(protocol v1.Protocol) {
+				ginkgo.By(fmt.Sprintf("Creating a network allowPort81Policy which only allows allow listed namespaces (y) to connect on exactly one port (81/%s)", protocol))
+				allowedLabels := &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						namespaceLabelKey: nsY,
+					},
+				}
+				ingressRule := networkingv1.NetworkPolicyIngressRule{}
+				ingressRule.From = append(ingressRule.From, networkingv1.NetworkPolicyPeer{NamespaceSelector: allowedLabels})
+				ingressRule.Ports = append(ingressRule.Ports, networkingv1.NetworkPolicyPort{Port: &intstr.IntOrString{IntVal: 81}, Protocol: &protocol})
+				allowPort81Policy := GenNetworkPolicyWithNameAndPodMatchLabel("allow-client-a-via-ns-selector", map[string]string{"pod": "a"}, SetSpecIngressRules(ingressRule))
+				CreatePolicy(ctx, k8s, allowPort81Policy, nsX)
+				defer func() {
+					_ = k8s.clientSet.NetworkingV1().NetworkPolicies(nsX).Delete(ctx, allowPort81Policy.Name, metav1.DeleteOptions{})
+				}()
+
+				reachability := NewReachability(k8s.AllPodStrings(), true)
+				reachability.ExpectPeer(&Peer{Namespace: nsX}, &Peer{Namespace: nsX, Pod: "a"}, false)
+				reachability.ExpectPeer(&Peer{Namespace: nsY}, &Peer{Namespace: nsX, Pod: "a"}, true)
+				reachability.ExpectPeer(&Peer{Namespace: nsZ}, &Peer{Namespace: nsX, Pod: "a"}, false)
+
+				ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: protocol, Reachability: reachability}, OracleModeFromEnv())
+			})
+		})
+
+		f.It("should enforce policy based on Ports with a port range via endPort", feature.NetworkPolicy, func(ctx context.Context) {
+			e2eskipper.SkipUnlessFeatureGateEnabled(features.NetworkPolicyEndPort)
+
+			protocols := []v1.Protocol{protocolTCP}
+			ports := []int32{79, 80, 81, 82, 83}
+			// Policy allows ingress to x/a from namespace Y across the port range
+			// 80-82 via endPort, so we include ports 79 and 83 (just below and just
+			// above the range, should stay denied) alongside the range itself, to tell
+			// "the range was honored" apart from "every port is open".
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b", "y/a", "z/a")
+			nsX, nsY, nsZ := getK8sNamespaces(k8s)
+
+			ginkgo.By("Creating a network policy which only allows listed namespaces (y) to connect on a port range (80-82)")
 			allowedLabels := &metav1.LabelSelector{
 				MatchLabels: map[string]string{
 					namespaceLabelKey: nsY,
 				},
 			}
+			endPort := int32(82)
 			ingressRule := networkingv1.NetworkPolicyIngressRule{}
 			ingressRule.From = append(ingressRule.From, networkingv1.NetworkPolicyPeer{NamespaceSelector: allowedLabels})
-			ingressRule.Ports = append(ingressRule.Ports, networkingv1.NetworkPolicyPort{Port: &intstr.IntOrString{IntVal: 81}, Protocol: &protocolTCP})
-			allowPort81Policy := GenNetworkPolicyWithNameAndPodMatchLabel("allow-client-a-via-ns-selector", map[string]string{"pod": "a"}, SetSpecIngressRules(ingressRule))
-			CreatePolicy(ctx, k8s, allowPort81Policy, nsX)
+			ingressRule.Ports = append(ingressRule.Ports, networkingv1.NetworkPolicyPort{Port: &intstr.IntOrString{IntVal: 80}, EndPort: &endPort, Protocol: &protocolTCP})
+			allowPortRangePolicy := GenNetworkPolicyWithNameAndPodMatchLabel("allow-client-a-port-range", map[string]string{"pod": "a"}, SetSpecIngressRules(ingressRule))
+			CreatePolicy(ctx, k8s, allowPortRangePolicy, nsX)
+
+			withinRange := NewReachability(k8s.AllPodStrings(), true)
+			withinRange.ExpectPeer(&Peer{Namespace: nsX}, &Peer{Namespace: nsX, Pod: "a"}, false)
+			withinRange.ExpectPeer(&Peer{Namespace: nsY}, &Peer{Namespace: nsX, Pod: "a"}, true)
+			withinRange.ExpectPeer(&Peer{Namespace: nsZ}, &Peer{Namespace: nsX, Pod: "a"}, false)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, EndPort: 82, Protocol: v1.ProtocolTCP, Reachability: withinRange}, OracleModeFromEnv())
+
+			belowRange := NewReachability(k8s.AllPodStrings(), true)
+			belowRange.ExpectAllIngress(NewPodString(nsX, "a"), false)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 79, Protocol: v1.ProtocolTCP, Reachability: belowRange}, OracleModeFromEnv())
+
+			aboveRange := NewReachability(k8s.AllPodStrings(), true)
+			aboveRange.ExpectAllIngress(NewPodString(nsX, "a"), false)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 83, Protocol: v1.ProtocolTCP, Reachability: aboveRange}, OracleModeFromEnv())
+		})
 
-			reachability := NewReachability(k8s.AllPodStrings(), true)
-			reachability.ExpectPeer(&Peer{Namespace: nsX}, &Peer{Namespace: nsX, Pod: "a"}, false)
-			reachability.ExpectPeer(&Peer{Namespace: nsY}, &Peer{Namespace: nsX, Pod: "a"}, true)
-			reachability.ExpectPeer(&Peer{Namespace: nsZ}, &Peer{Namespace: nsX, Pod: "a"}, false)
+		f.It("should reject a NetworkPolicyPort that combines a named Port with EndPort", feature.NetworkPolicy, func(ctx context.Context) {
+			e2eskipper.SkipUnlessFeatureGateEnabled(features.NetworkPolicyEndPort)
+
+			protocols := []v1.Protocol{protocolTCP}
+			ports := []int32{80}
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			endPort := int32(82)
+			ingressRule := networkingv1.NetworkPolicyIngressRule{}
+			ingressRule.Ports = append(ingressRule.Ports, networkingv1.NetworkPolicyPort{
+				Port:    &intstr.IntOrString{Type: intstr.String, StrVal: "serve-80-tcp"},
+				EndPort: &endPort,
+			})
+			policy := GenNetworkPolicyWithNameAndPodMatchLabel("reject-named-port-with-endport", map[string]string{"pod": "a"}, SetSpecIngressRules(ingressRule))
+
+			_, err := k8s.clientSet.NetworkingV1().NetworkPolicies(nsX).Create(ctx, policy, metav1.CreateOptions{})
+			gomega.Expect(err).To(gomega.HaveOccurred(), "the API server must reject a NetworkPolicyPort that sets EndPort alongside a named Port")
+		})
+
+		f.It("should reject a NetworkPolicyPort whose EndPort is below its Port", feature.NetworkPolicy, func(ctx context.Context) {
+			e2eskipper.SkipUnlessFeatureGateEnabled(features.NetworkPolicyEndPort)
+
+			protocols := []v1.Protocol{protocolTCP}
+			ports := []int32{80}
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			policy := GenNetworkPolicyWithNameAndPodMatchLabel("reject-inverted-port-range", map[string]string{"pod": "a"}, SetSpecIngressRulesWithPortRange(80, 79, protocolTCP))
+
+			_, err := k8s.clientSet.NetworkingV1().NetworkPolicies(nsX).Create(ctx, policy, metav1.CreateOptions{})
+			gomega.Expect(err).To(gomega.HaveOccurred(), "the API server must reject a NetworkPolicyPort whose EndPort is below its Port")
+		})
+
+		f.It("should enforce a wider port range via endPort and deny ports just outside it", feature.NetworkPolicy, func(ctx context.Context) {
+			e2eskipper.SkipUnlessFeatureGateEnabled(features.NetworkPolicyEndPort)
+
+			protocols := []v1.Protocol{protocolTCP}
+			ports := []int32{79, 80, 83, 85, 86}
+			// Policy allows ingress to x/a on the full range 80-85 from any peer; we
+			// probe 79 (just below), 80/83/85 (inside, including both ends), and 86
+			// (just above) to confirm the range's boundaries are honored exactly.
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			policy := GenNetworkPolicyWithNameAndPodMatchLabel("allow-client-a-wide-port-range", map[string]string{"pod": "a"}, SetSpecIngressRulesWithPortRange(80, 85, protocolTCP))
+			CreatePolicy(ctx, k8s, policy, nsX)
+
+			withinRange := NewReachability(k8s.AllPodStrings(), true)
+			for _, port := range []int{80, 83, 85} {
+				ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: port, Protocol: v1.ProtocolTCP, Reachability: withinRange}, OracleModeFromEnv())
+			}
+
+			outsideRange := NewReachability(k8s.AllPodStrings(), true)
+			outsideRange.ExpectAllIngress(NewPodString(nsX, "a"), false)
+			for _, port := range []int{79, 86} {
+				ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: port, Protocol: v1.ProtocolTCP, Reachability: outsideRange}, OracleModeFromEnv())
+			}
 		})
 
 		f.It("should enforce multiple, stacked policies with overlapping podSelectors", feature.NetworkPolicy, func(ctx context.Context) {
@@ -492,13 +801,13 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachabilityALLOW.ExpectPeer(&Peer{Namespace: nsZ}, &Peer{Namespace: nsX, Pod: "a"}, false)
 
 			ginkgo.By("Verifying traffic on port 81.")
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachabilityALLOW})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachabilityALLOW}, OracleModeFromEnv())
 
 			reachabilityDENY := NewReachability(k8s.AllPodStrings(), true)
 			reachabilityDENY.ExpectAllIngress(NewPodString(nsX, "a"), false)
 
 			ginkgo.By("Verifying traffic on port 80.")
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityDENY})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityDENY}, OracleModeFromEnv())
 
 			ingressRule = networkingv1.NetworkPolicyIngressRule{}
 			ingressRule.From = append(ingressRule.From, networkingv1.NetworkPolicyPeer{NamespaceSelector: allowedLabels})
@@ -507,7 +816,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			CreatePolicy(ctx, k8s, allowPort80Policy, nsX)
 
 			ginkgo.By("Verifying that we can add a policy to unblock port 80")
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityALLOW})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityALLOW}, OracleModeFromEnv())
 		})
 
 		f.It("should support allow-all policy", feature.NetworkPolicy, func(ctx context.Context) {
@@ -524,8 +833,8 @@ var _ = common.SIGDescribe("Netpol", func() {
 
 			ginkgo.By("Testing pods can connect to both ports when an 'allow-all' policy is present.")
 			reachability := NewReachability(k8s.AllPodStrings(), true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should allow ingress access on one named port", feature.NetworkPolicy, func(ctx context.Context) {
@@ -544,12 +853,12 @@ var _ = common.SIGDescribe("Netpol", func() {
 			CreatePolicy(ctx, k8s, policy, nsX)
 
 			reachabilityPort81 := NewReachability(k8s.AllPodStrings(), true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachabilityPort81})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachabilityPort81}, OracleModeFromEnv())
 
 			// disallow all traffic to the x namespace
 			reachabilityPort80 := NewReachability(k8s.AllPodStrings(), true)
 			reachabilityPort80.ExpectPeer(&Peer{}, &Peer{Namespace: nsX}, false)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityPort80})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityPort80}, OracleModeFromEnv())
 		})
 
 		f.It("should allow ingress access from namespace on one named port", feature.NetworkPolicy, func(ctx context.Context) {
@@ -579,12 +888,12 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability.ExpectPeer(&Peer{Namespace: nsZ}, &Peer{Namespace: nsX, Pod: "a"}, false)
 
 			ginkgo.By("Verify that port 80 is allowed for namespace y")
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 
 			ginkgo.By("Verify that port 81 is blocked for all namespaces including y")
 			reachabilityFAIL := NewReachability(k8s.AllPodStrings(), true)
 			reachabilityFAIL.ExpectAllIngress(NewPodString(nsX, "a"), false)
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachabilityFAIL})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachabilityFAIL}, OracleModeFromEnv())
 		})
 
 		f.It("should allow egress access on one named port", feature.NetworkPolicy, func(ctx context.Context) {
@@ -602,12 +911,12 @@ var _ = common.SIGDescribe("Netpol", func() {
 			CreatePolicy(ctx, k8s, policy, nsX)
 
 			reachabilityPort80 := NewReachability(k8s.AllPodStrings(), true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityPort80})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityPort80}, OracleModeFromEnv())
 
 			// meanwhile no traffic over 81 should work, since our egress policy is on 80
 			reachabilityPort81 := NewReachability(k8s.AllPodStrings(), true)
 			reachabilityPort81.ExpectPeer(&Peer{Namespace: nsX}, &Peer{}, false)
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachabilityPort81})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachabilityPort81}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce updated policy", feature.NetworkPolicy, func(ctx context.Context) {
@@ -625,7 +934,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			CreatePolicy(ctx, k8s, policy, nsX)
 
 			reachability := NewReachability(k8s.AllPodStrings(), true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 
 			// part 2) update the policy to deny all
 			policy.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{}
@@ -633,7 +942,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 
 			reachabilityDeny := NewReachability(k8s.AllPodStrings(), true)
 			reachabilityDeny.ExpectPeer(&Peer{}, &Peer{Namespace: nsX}, false)
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachabilityDeny})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachabilityDeny}, OracleModeFromEnv())
 		})
 
 		f.It("should allow ingress access from updated namespace", feature.NetworkPolicy, func(ctx context.Context) {
@@ -657,7 +966,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 
 			// add a new label
 			AddNamespaceLabel(ctx, k8s, nsY, "ns2", "updated")
@@ -666,7 +975,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachabilityWithLabel := NewReachability(k8s.AllPodStrings(), true)
 			reachabilityWithLabel.ExpectAllIngress(NewPodString(nsX, "a"), false)
 			reachabilityWithLabel.ExpectPeer(&Peer{Namespace: nsY}, &Peer{}, true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityWithLabel})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityWithLabel}, OracleModeFromEnv())
 		})
 
 		f.It("should allow ingress access from updated pod", feature.NetworkPolicy, func(ctx context.Context) {
@@ -688,7 +997,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 
 			AddPodLabels(ctx, k8s, nsX, "b", matchLabels)
 
@@ -697,7 +1006,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachabilityWithLabel := NewReachability(k8s.AllPodStrings(), true)
 			reachabilityWithLabel.ExpectAllIngress(NewPodString(nsX, "a"), false)
 			reachabilityWithLabel.Expect(NewPodString(nsX, "b"), NewPodString(nsX, "a"), true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityWithLabel})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityWithLabel}, OracleModeFromEnv())
 		})
 
 		f.It("should deny ingress from pods on other namespaces", feature.NetworkPolicy, func(ctx context.Context) {
@@ -718,7 +1027,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability.ExpectPeer(&Peer{Namespace: nsY}, &Peer{Namespace: nsX}, false)
 			reachability.ExpectPeer(&Peer{Namespace: nsZ}, &Peer{Namespace: nsX}, false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should deny ingress access to updated pod", feature.NetworkPolicy, func(ctx context.Context) {
@@ -737,13 +1046,13 @@ var _ = common.SIGDescribe("Netpol", func() {
 
 			ginkgo.By("Verify that everything can reach x/a")
 			reachability := NewReachability(k8s.AllPodStrings(), true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 
 			AddPodLabels(ctx, k8s, nsX, "a", map[string]string{"target": "isolated"})
 
 			reachabilityIsolated := NewReachability(k8s.AllPodStrings(), true)
 			reachabilityIsolated.ExpectAllIngress(NewPodString(nsX, "a"), false)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityIsolated})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityIsolated}, OracleModeFromEnv())
 		})
 
 		f.It("should deny egress from pods based on PodSelector", feature.NetworkPolicy, func(ctx context.Context) {
@@ -760,7 +1069,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.ExpectAllEgress(NewPodString(nsX, "a"), false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should deny egress from all pods in a namespace", feature.NetworkPolicy, func(ctx context.Context) {
@@ -777,7 +1086,40 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.ExpectPeer(&Peer{Namespace: nsX}, &Peer{}, false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
+		})
+
+		f.It("should deny egress from pods based on PodSelector while still allowing DNS and node egress", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolUDP}
+			ports := []int32{80}
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b", "y/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			policy := GenNetworkPolicyWithNameAndPodSelector("deny-egress-pod-a-essentials", metav1.LabelSelector{MatchLabels: map[string]string{"pod": "a"}}, SetSpecEgressRulesWithDNSAndNode(ctx, k8s))
+			CreatePolicy(ctx, k8s, policy, nsX)
+
+			reachability := NewReachability(k8s.AllPodStrings(), true)
+			reachability.ExpectAllEgress(NewPodString(nsX, "a"), false)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolUDP, Reachability: reachability}, OracleModeFromEnv())
+
+			assertDNSAndNodeEgressStillWork(ctx, f, k8s, &Pod{Namespace: nsX, Name: "a"})
+		})
+
+		f.It("should deny egress from all pods in a namespace while still allowing DNS and node egress", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolUDP}
+			ports := []int32{80}
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b", "y/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			policy := GenNetworkPolicyWithNameAndPodSelector("deny-egress-ns-x-essentials", metav1.LabelSelector{}, SetSpecEgressRulesWithDNSAndNode(ctx, k8s))
+			CreatePolicy(ctx, k8s, policy, nsX)
+
+			reachability := NewReachability(k8s.AllPodStrings(), true)
+			reachability.ExpectPeer(&Peer{Namespace: nsX}, &Peer{}, false)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolUDP, Reachability: reachability}, OracleModeFromEnv())
+
+			assertDNSAndNodeEgressStillWork(ctx, f, k8s, &Pod{Namespace: nsX, Name: "a"})
+			assertDNSAndNodeEgressStillWork(ctx, f, k8s, &Pod{Namespace: nsX, Name: "b"})
 		})
 
 		f.It("should work with Ingress, Egress specified together", feature.NetworkPolicy, func(ctx context.Context) {
@@ -811,7 +1153,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachabilityPort80 := NewReachability(k8s.AllPodStrings(), true)
 			reachabilityPort80.ExpectAllIngress(NewPodString(nsX, "a"), false)
 			reachabilityPort80.Expect(NewPodString(nsX, "b"), NewPodString(nsX, "a"), true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityPort80})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityPort80}, OracleModeFromEnv())
 
 			ginkgo.By("validating that port 81 doesn't work")
 			// meanwhile no egress traffic on 81 should work, since our egress policy is on 80
@@ -819,7 +1161,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachabilityPort81.ExpectAllIngress(NewPodString(nsX, "a"), false)
 			reachabilityPort81.ExpectAllEgress(NewPodString(nsX, "a"), false)
 			reachabilityPort81.Expect(NewPodString(nsX, "b"), NewPodString(nsX, "a"), true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachabilityPort81})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachabilityPort81}, OracleModeFromEnv())
 		})
 
 		f.It("should support denying of egress traffic on the client side (even if the server explicitly allows this traffic)", feature.NetworkPolicy, func(ctx context.Context) {
@@ -877,7 +1219,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability.ExpectPeer(&Peer{Namespace: nsX, Pod: "a"}, &Peer{Namespace: nsY, Pod: "a"}, true)
 			reachability.ExpectPeer(&Peer{}, &Peer{Namespace: nsY, Pod: "b"}, false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce egress policy allowing traffic to a server in a different namespace based on PodSelector and NamespaceSelector", feature.NetworkPolicy, func(ctx context.Context) {
@@ -906,7 +1248,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.ExpectAllEgress(NewPodString(nsX, "a"), false)
 			reachability.Expect(NewPodString(nsX, "a"), NewPodString(nsY, "a"), true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce ingress policy allowing any port traffic to a server on a specific protocol", feature.NetworkPolicy, func(ctx context.Context) {
@@ -923,11 +1265,11 @@ var _ = common.SIGDescribe("Netpol", func() {
 			CreatePolicy(ctx, k8s, policy, nsX)
 
 			reachabilityTCP := NewReachability(k8s.AllPodStrings(), true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityTCP})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityTCP}, OracleModeFromEnv())
 
 			reachabilityUDP := NewReachability(k8s.AllPodStrings(), true)
 			reachabilityUDP.ExpectPeer(&Peer{}, &Peer{Namespace: nsX, Pod: "a"}, false)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolUDP, Reachability: reachabilityUDP})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolUDP, Reachability: reachabilityUDP}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce multiple ingress policies with ingress allow-all policy taking precedence", feature.NetworkPolicy, func(ctx context.Context) {
@@ -947,7 +1289,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.ExpectPeer(&Peer{}, &Peer{Namespace: nsX}, false)
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 
 			ginkgo.By("Allowing all ports")
 
@@ -955,7 +1297,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			CreatePolicy(ctx, k8s, policyAllowAll, nsX)
 
 			reachabilityAll := NewReachability(k8s.AllPodStrings(), true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachabilityAll})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachabilityAll}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce multiple egress policies with egress allow-all policy taking precedence", feature.NetworkPolicy, func(ctx context.Context) {
@@ -975,7 +1317,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.ExpectPeer(&Peer{Namespace: nsX}, &Peer{}, false)
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 
 			ginkgo.By("Allowing all ports")
 
@@ -983,7 +1325,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			CreatePolicy(ctx, k8s, policyAllowAll, nsX)
 
 			reachabilityAll := NewReachability(k8s.AllPodStrings(), true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachabilityAll})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachabilityAll}, OracleModeFromEnv())
 		})
 
 		f.It("should stop enforcing policies after they are deleted", feature.NetworkPolicy, func(ctx context.Context) {
@@ -1003,15 +1345,16 @@ var _ = common.SIGDescribe("Netpol", func() {
 			// Expect all traffic into, and out of "x" to be False.
 			reachability.ExpectPeer(&Peer{Namespace: nsX}, &Peer{}, false)
 			reachability.ExpectPeer(&Peer{}, &Peer{Namespace: nsX}, false)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 
 			err := k8s.cleanNetworkPolicies(ctx)
 			time.Sleep(3 * time.Second) // TODO we can remove this eventually, its just a hack to keep CI stable.
 			framework.ExpectNoError(err, "unable to clean network policies")
+			VerifyOracleCleanupOrFail(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP}, OracleModeFromEnv())
 
 			// Now the policy is deleted, we expect all connectivity to work again.
 			reachabilityAll := NewReachability(k8s.AllPodStrings(), true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityAll})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityAll}, OracleModeFromEnv())
 		})
 
 		// TODO, figure out how the next 3 tests should work with dual stack : do we need a different abstraction then just "podIP"?
@@ -1044,7 +1387,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.ExpectAllEgress(NewPodString(nsX, "a"), false)
 			reachability.Expect(NewPodString(nsX, "a"), NewPodString(nsY, "b"), true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce except clause while egress access to server in CIDR block", feature.NetworkPolicy, func(ctx context.Context) {
@@ -1063,7 +1406,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			// Create a rule that allows egress to a large set of IPs around
 			// podB, but not podB itself.
 
-			podServerAllowCIDR := makeLargeCIDRForIP(podB.Status.PodIP)
+			podServerAllowCIDR := makeBroadCIDRForIP(podB.Status.PodIP)
 			hostMask := 32
 			if utilnet.IsIPv6String(podB.Status.PodIP) {
 				hostMask = 128
@@ -1079,7 +1422,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.Expect(NewPodString(nsX, "a"), NewPodString(nsX, "b"), false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should ensure an IP overlapping both IPBlock.CIDR and IPBlock.Except is allowed", feature.NetworkPolicy, func(ctx context.Context) {
@@ -1099,7 +1442,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			// Create a rule that allows egress to a large set of IPs around
 			// podB, but not podB itself.
 
-			podServerAllowCIDR := makeLargeCIDRForIP(podB.Status.PodIP)
+			podServerAllowCIDR := makeBroadCIDRForIP(podB.Status.PodIP)
 			hostMask := 32
 			if utilnet.IsIPv6String(podB.Status.PodIP) {
 				hostMask = 128
@@ -1114,7 +1457,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.Expect(NewPodString(nsX, "a"), NewPodString(nsX, "b"), false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 
 			// Create a second NetworkPolicy which allows access to podB
 			podBIP := fmt.Sprintf("%s/%d", podB.Status.PodIP, hostMask)
@@ -1129,7 +1472,101 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachabilityAllow.ExpectAllEgress(NewPodString(nsX, "a"), false)
 			reachabilityAllow.Expect(NewPodString(nsX, "a"), NewPodString(nsX, "b"), true)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityAllow})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachabilityAllow}, OracleModeFromEnv())
+		})
+
+		f.It("should ignore a mismatched-family CIDR in an ipBlock except list", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolTCP}
+			ports := []int32{80}
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			podB, err := f.ClientSet.CoreV1().Pods(nsX).Get(ctx, "b", metav1.GetOptions{})
+			framework.ExpectNoError(err, "getting pod %s/b", nsX)
+
+			// Except podB's own family entirely - if the apiserver/CNI correctly ignore an
+			// except entry of the wrong family for the CIDR it's attached to, podB stays
+			// reachable; if they don't, this except wrongly excepts everything.
+			mismatchedFamilyExcept := "::/0"
+			if utilnet.IsIPv6String(podB.Status.PodIP) {
+				mismatchedFamilyExcept = "0.0.0.0/0"
+			}
+
+			egressRule := networkingv1.NetworkPolicyEgressRule{}
+			egressRule.To = append(egressRule.To, networkingv1.NetworkPolicyPeer{IPBlock: &networkingv1.IPBlock{
+				CIDR:   makeBroadCIDRForIP(podB.Status.PodIP),
+				Except: []string{mismatchedFamilyExcept},
+			}})
+			policy := GenNetworkPolicyWithNameAndPodMatchLabel("allow-client-a-via-cidr-mismatched-except",
+				map[string]string{"pod": "a"}, SetSpecEgressRules(egressRule))
+			CreatePolicy(ctx, k8s, policy, nsX)
+
+			reachability := NewReachability(k8s.AllPodStrings(), true)
+			reachability.ExpectAllEgress(NewPodString(nsX, "a"), false)
+			reachability.Expect(NewPodString(nsX, "a"), NewPodString(nsX, "b"), true)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
+		})
+
+		f.It("should allow ingress from both IPv4 and IPv6 ipBlock peers in a dual-stack cluster", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolTCP}
+			ports := []int32{80}
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			clientPod, err := f.ClientSet.CoreV1().Pods(nsX).Get(ctx, "b", metav1.GetOptions{})
+			framework.ExpectNoError(err, "getting pod %s/b", nsX)
+			clientV4, clientV6 := podIPsByFamily(clientPod)
+			if clientV4 == "" || clientV6 == "" {
+				e2eskipper.Skipf("cluster is not dual-stack: pod %s/b has PodIPs %v", nsX, clientPod.Status.PodIPs)
+			}
+
+			ingressRule := networkingv1.NetworkPolicyIngressRule{
+				From: []networkingv1.NetworkPolicyPeer{
+					{IPBlock: &networkingv1.IPBlock{CIDR: makeTightCIDRForIP(clientV4)}},
+					{IPBlock: &networkingv1.IPBlock{CIDR: makeTightCIDRForIP(clientV6)}},
+				},
+			}
+			policy := GenNetworkPolicyWithNameAndPodMatchLabel("allow-dual-stack-ipblock-ingress",
+				map[string]string{"pod": "a"}, SetSpecIngressRules(ingressRule))
+			CreatePolicy(ctx, k8s, policy, nsX)
+
+			serverPod, err := f.ClientSet.CoreV1().Pods(nsX).Get(ctx, "a", metav1.GetOptions{})
+			framework.ExpectNoError(err, "getting pod %s/a", nsX)
+			serverV4, serverV6 := podIPsByFamily(serverPod)
+			client := &Pod{Namespace: nsX, Name: "b"}
+
+			ginkgo.By("checking that the IPv4 ipBlock peer is reachable")
+			connected, err := probeAddress(k8s, client, 80, v1.ProtocolTCP, serverV4, 80, v1.ProtocolTCP)
+			framework.ExpectNoError(err, "probing %s/b -> %s over IPv4", nsX, serverV4)
+			if !connected {
+				framework.Failf("expected %s/b to reach %s/a over IPv4", nsX, nsX)
+			}
+
+			ginkgo.By("checking that the IPv6 ipBlock peer is reachable")
+			connected, err = probeAddress(k8s, client, 80, v1.ProtocolTCP, serverV6, 80, v1.ProtocolTCP)
+			framework.ExpectNoError(err, "probing %s/b -> %s over IPv6", nsX, serverV6)
+			if !connected {
+				framework.Failf("expected %s/b to reach %s/a over IPv6", nsX, nsX)
+			}
+		})
+
+		f.It("should allow DNS and node-local egress while enforcing a default-deny-egress policy", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolUDP}
+			ports := []int32{80}
+			// Only x/a needs its egress restricted; y/a is enough to prove its
+			// model-to-model egress is otherwise blocked.
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "y/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			policy := AllowDNSAndNodeEgress(ctx, k8s)
+			CreatePolicy(ctx, k8s, policy, nsX)
+
+			ginkgo.By("checking that x/a's egress to other pods is still blocked")
+			reachability := NewReachability(k8s.AllPodStrings(), true)
+			reachability.ExpectAllEgress(NewPodString(nsX, "a"), false)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolUDP, Reachability: reachability}, OracleModeFromEnv())
+
+			assertDNSAndNodeEgressStillWork(ctx, f, k8s, &Pod{Namespace: nsX, Name: "a"})
 		})
 
 		f.It("should enforce policies to check ingress and egress policies can be controlled independently based on PodSelector", feature.NetworkPolicy, func(ctx context.Context) {
@@ -1158,7 +1595,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			CreatePolicy(ctx, k8s, allowEgressPolicy, nsX)
 
 			allowEgressReachability := NewReachability(k8s.AllPodStrings(), true)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: allowEgressReachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: allowEgressReachability}, OracleModeFromEnv())
 
 			ginkgo.By("Creating a network policy for pod-a that denies traffic from pod-b.")
 
@@ -1167,7 +1604,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 
 			denyIngressToXReachability := NewReachability(k8s.AllPodStrings(), true)
 			denyIngressToXReachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: denyIngressToXReachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: denyIngressToXReachability}, OracleModeFromEnv())
 		})
 
 		// This test *does* apply to plugins that do not implement SCTP. It is a
@@ -1195,7 +1632,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			ginkgo.By("Trying to connect to TCP port 81, which should be blocked by the deny-ingress policy.")
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.ExpectPeer(&Peer{}, &Peer{Namespace: nsX}, false)
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		// This test *does* apply to plugins that do not implement SCTP. It is a
@@ -1218,7 +1655,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			ginkgo.By("Trying to connect to TCP port 81, which should be blocked by implicit isolation.")
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should not allow access by TCP when a policy specifies only UDP", feature.NetworkPolicy, func(ctx context.Context) {
@@ -1239,7 +1676,60 @@ var _ = common.SIGDescribe("Netpol", func() {
 			// Probing with TCP, so all traffic should be dropped.
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
+		})
+
+		f.It("should match the Reachability simulated from randomized, generated NetworkPolicies", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolTCP, protocolUDP, protocolSCTP}
+			ports := []int32{80, 81, 82}
+			// A richer topology than the hand-written cases above gives the fuzzer's
+			// PodSelector/NamespaceSelector/ipBlock peers more to select between.
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b", "x/c", "y/a", "y/b", "z/a")
+			nsX, nsY, nsZ := getK8sNamespaces(k8s)
+
+			nsLabels, err := k8s.NamespaceLabels(ctx)
+			framework.ExpectNoError(err, "fetching namespace labels for the fuzzer's topology")
+
+			topo := generator.Topology{NamespaceLabels: nsLabels}
+			for _, pod := range k8s.model.AllPods() {
+				livePod, err := f.ClientSet.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+				framework.ExpectNoError(err, "fetching pod %s/%s for the fuzzer's topology", pod.Namespace, pod.Name)
+				topo.Pods = append(topo.Pods, generator.Pod{
+					Namespace: pod.Namespace,
+					Name:      pod.Name,
+					Labels:    pod.Labels,
+					IP:        livePod.Status.PodIP,
+				})
+			}
+			cfg := generator.Config{
+				PodLabels:  []string{"a", "b", "c"},
+				Namespaces: []string{nsX, nsY, nsZ},
+				Ports:      ports,
+				Protocols:  protocols,
+			}
+
+			seed := time.Now().UnixNano()
+			framework.Logf("netpol fuzzer seed: %d (pass this to reproduce a failure)", seed)
+			rng := rand.New(rand.NewSource(seed))
+
+			const numGeneratedPolicies = 5
+			for i := 0; i < numGeneratedPolicies; i++ {
+				policy := generator.GeneratePolicy(rng, fmt.Sprintf("fuzz-%d", i), nsX, topo, cfg)
+				framework.Logf("fuzzer iteration %d: %+v", i, policy.Spec)
+				CreatePolicy(ctx, k8s, policy, nsX)
+
+				simulated := generator.Simulate(topo, []*networkingv1.NetworkPolicy{policy}, ports[0], protocols[0])
+				reachability := NewReachability(k8s.AllPodStrings(), true)
+				for from, tos := range simulated {
+					for to, connected := range tos {
+						reachability.Expect(PodString(from), PodString(to), connected)
+					}
+				}
+				ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: int(ports[0]), Protocol: protocols[0], Reachability: reachability}, OracleModeFromEnv())
+
+				err := k8s.clientSet.NetworkingV1().NetworkPolicies(nsX).Delete(ctx, policy.Name, metav1.DeleteOptions{})
+				framework.ExpectNoError(err, "deleting fuzzer-generated policy %s before the next iteration", policy.Name)
+			}
 		})
 
 		// Note that this default ns functionality is maintained by the APIMachinery group, but we test it here anyways because its an important feature.
@@ -1266,7 +1756,7 @@ var _ = common.SIGDescribe("Netpol", func() {
 			reachability.ExpectPeer(&Peer{Namespace: nsX}, &Peer{Namespace: nsX, Pod: "a"}, false)
 			reachability.ExpectPeer(&Peer{Namespace: nsZ}, &Peer{Namespace: nsX, Pod: "a"}, false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		// Note that this default ns functionality is maintained by the APIMachinery group, but we test it here anyways because its an important feature.
@@ -1293,7 +1783,171 @@ var _ = common.SIGDescribe("Netpol", func() {
 
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.ExpectPeer(&Peer{Namespace: nsX, Pod: "a"}, &Peer{Namespace: nsY}, false)
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
+		})
+	})
+
+	ginkgo.Context("NetworkPolicy with Node peers", func() {
+		var k8s *kubeManager
+
+		f.It("should allow egress to worker nodes while denying egress to the control-plane node", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolTCP}
+			ports := []int32{80}
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			nodes, err := f.ClientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+			framework.ExpectNoError(err, "listing nodes")
+			var controlPlaneName string
+			var workerNames []string
+			for _, node := range nodes.Items {
+				if _, ok := node.Labels["node-role.kubernetes.io/control-plane"]; ok {
+					controlPlaneName = node.Name
+				} else {
+					workerNames = append(workerNames, node.Name)
+				}
+			}
+			if controlPlaneName == "" || len(workerNames) == 0 {
+				e2eskipper.Skipf("need a labeled control-plane node and at least one worker node to test node-selector egress")
+			}
+
+			_, err = k8s.EnsureNodeProbePods(ctx, []int32{6443}, protocols)
+			framework.ExpectNoError(err, "creating host-network node probe pods")
+
+			workerSelector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      "node-role.kubernetes.io/control-plane",
+					Operator: metav1.LabelSelectorOpDoesNotExist,
+				}},
+			})
+			framework.ExpectNoError(err, "building worker node selector")
+
+			policy := GenNetworkPolicyWithNameAndPodMatchLabel("allow-egress-to-worker-nodes", map[string]string{"pod": "a"},
+				SetSpecEgressToNodeSelector(ctx, k8s, workerSelector, networkingv1.NetworkPolicyPort{Port: &intstr.IntOrString{IntVal: 6443}, Protocol: &protocolTCP}))
+			CreatePolicy(ctx, k8s, policy, nsX)
+
+			reachability := NewReachability(k8s.AllPodStrings(), true)
+			reachability.ExpectAllEgress(NewPodString(nsX, "a"), false)
+			for _, name := range workerNames {
+				reachability.ExpectPeer(&Peer{Namespace: nsX, Pod: "a"}, &Peer{Node: name}, true)
+			}
+
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 6443, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
+		})
+
+		f.It("should allow ingress from a selected node while denying ingress from other nodes", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolTCP}
+			ports := []int32{80}
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			nodePods, err := k8s.EnsureNodeProbePods(ctx, ports, protocols)
+			framework.ExpectNoError(err, "creating host-network node probe pods")
+			if len(nodePods) < 2 {
+				e2eskipper.Skipf("need at least 2 nodes to test node-selector ingress, found %d", len(nodePods))
+			}
+
+			nodes, err := f.ClientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+			framework.ExpectNoError(err, "listing nodes")
+			selectedNode := nodes.Items[0]
+			const hostnameLabelKey = "kubernetes.io/hostname"
+
+			nodeSelector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+				MatchLabels: map[string]string{hostnameLabelKey: selectedNode.Labels[hostnameLabelKey]},
+			})
+			framework.ExpectNoError(err, "building node selector")
+
+			policy := GenNetworkPolicyWithNameAndPodMatchLabel("allow-ingress-from-selected-node", map[string]string{"pod": "a"},
+				SetSpecIngressFromNodeSelector(ctx, k8s, nodeSelector, networkingv1.NetworkPolicyPort{Port: &intstr.IntOrString{IntVal: 80}, Protocol: &protocolTCP}))
+			CreatePolicy(ctx, k8s, policy, nsX)
+
+			reachability := NewReachability(k8s.AllPodStrings(), true)
+			reachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
+			reachability.ExpectPeer(&Peer{Node: selectedNode.Name}, &Peer{Namespace: nsX, Pod: "a"}, true)
+
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: reachability}, OracleModeFromEnv())
+		})
+	})
+
+	ginkgo.Context("NetworkPolicy convergence", func() {
+		var k8s *kubeManager
+
+		f.It("should converge to a default-deny-then-allow transition within a budget", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolTCP}
+			ports := []int32{80}
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b", "y/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			ginkgo.By("creating a default-deny-ingress policy on x/a")
+			denyPolicy := GenNetworkPolicyWithNameAndPodMatchLabel("deny-ingress-for-convergence", map[string]string{"pod": "a"}, SetSpecIngressRules())
+			CreatePolicy(ctx, k8s, denyPolicy, nsX)
+
+			denyReachability := NewReachability(k8s.AllPodStrings(), true)
+			denyReachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
+			tc := &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: denyReachability}
+			ValidateConvergenceOrFail(ctx, k8s, tc, time.Now(), ConvergenceOpts{Deadline: *convergenceBudget})
+
+			ginkgo.By("updating the policy to allow ingress from x/b")
+			allowRule := networkingv1.NetworkPolicyIngressRule{
+				From: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pod": "b"}}}},
+			}
+			allowPolicy := GenNetworkPolicyWithNameAndPodMatchLabel("deny-ingress-for-convergence", map[string]string{"pod": "a"}, SetSpecIngressRules(allowRule))
+			since := time.Now()
+			UpdatePolicy(ctx, k8s, allowPolicy, nsX)
+
+			allowReachability := NewReachability(k8s.AllPodStrings(), true)
+			allowReachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
+			allowReachability.ExpectPeer(&Peer{Namespace: nsX, Pod: "b"}, &Peer{Namespace: nsX, Pod: "a"}, true)
+			tc = &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP, Reachability: allowReachability}
+			ValidateConvergenceOrFail(ctx, k8s, tc, since, ConvergenceOpts{Deadline: *convergenceBudget})
+		})
+	})
+
+	// This Context demonstrates the declarative Scenario DSL (see scenario.go) by
+	// expressing two of the cases above - "default-deny-ingress" and "enforce policy
+	// based on Ports" - as data instead of procedural ginkgo bodies. It's the shape a
+	// CNI vendor contributing cases via RunScenarios would follow.
+	//
+	// This intentionally stops short of converting the rest of this file: rewriting
+	// every existing f.It here as a Scenario is a large, risk-bearing change to this
+	// suite's canonical coverage in its own right, and belongs in a follow-up that can
+	// be reviewed case-by-case rather than folded into introducing the DSL itself. The
+	// hand-written originals above remain the suite's source of truth until then.
+	ginkgo.Context("NetworkPolicy scenarios (declarative DSL)", func() {
+		RunScenarios(f, []Scenario{
+			{
+				Name:      "should support a 'default-deny-ingress' policy [Scenario]",
+				Pods:      []string{"x/a", "x/b", "y/a", "y/b"},
+				Protocols: []v1.Protocol{protocolTCP},
+				Ports:     []int32{80},
+				Policies: []PolicyBuilder{
+					func(nsX, _, _ string) (*networkingv1.NetworkPolicy, string) {
+						return GenNetworkPolicyWithNameAndPodSelector("deny-ingress", metav1.LabelSelector{}, SetSpecIngressRules()), nsX
+					},
+				},
+				Expectations: []Expectation{
+					{From: &Peer{}, To: &Peer{Namespace: "x"}, Port: 80, Protocol: protocolTCP, Connected: false},
+				},
+			},
+			{
+				Name:      "should enforce policy based on Ports [Scenario]",
+				Pods:      []string{"x/a", "x/b", "y/a", "z/a"},
+				Protocols: []v1.Protocol{protocolTCP},
+				Ports:     []int32{81},
+				Policies: []PolicyBuilder{
+					func(nsX, nsY, _ string) (*networkingv1.NetworkPolicy, string) {
+						ingressRule := networkingv1.NetworkPolicyIngressRule{
+							From:  []networkingv1.NetworkPolicyPeer{{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{namespaceLabelKey: nsY}}}},
+							Ports: []networkingv1.NetworkPolicyPort{{Port: &intstr.IntOrString{IntVal: 81}, Protocol: &protocolTCP}},
+						}
+						return GenNetworkPolicyWithNameAndPodMatchLabel("allow-ingress-on-port-81-ns-x", map[string]string{"pod": "a"}, SetSpecIngressRules(ingressRule)), nsX
+					},
+				},
+				Expectations: []Expectation{
+					{From: &Peer{Namespace: "x"}, To: &Peer{Namespace: "x", Pod: "a"}, Port: 81, Protocol: protocolTCP, Connected: false},
+					{From: &Peer{Namespace: "z"}, To: &Peer{Namespace: "x", Pod: "a"}, Port: 81, Protocol: protocolTCP, Connected: false},
+				},
+			},
 		})
 	})
 })
@@ -1325,7 +1979,7 @@ var _ = common.SIGDescribe("Netpol [LinuxOnly]", func() {
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.ExpectPeer(&Peer{}, &Peer{Namespace: nsX}, false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolUDP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolUDP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce policy based on Ports", feature.NetworkPolicy, func(ctx context.Context) {
@@ -1354,7 +2008,61 @@ var _ = common.SIGDescribe("Netpol [LinuxOnly]", func() {
 			reachability.ExpectPeer(&Peer{Namespace: nsX}, &Peer{Namespace: nsX, Pod: "a"}, false)
 			reachability.ExpectPeer(&Peer{Namespace: nsZ}, &Peer{Namespace: nsX, Pod: "a"}, false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolUDP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolUDP, Reachability: reachability}, OracleModeFromEnv())
+		})
+
+		f.It("should enforce policy based on Ports with a port range via endPort, combined with a namespaceSelector", feature.NetworkPolicy, func(ctx context.Context) {
+			e2eskipper.SkipUnlessFeatureGateEnabled(features.NetworkPolicyEndPort)
+
+			protocols := []v1.Protocol{protocolUDP}
+			ports := []int32{79, 80, 81, 82}
+			// UDP: namespace X should allow ingress to x/a from namespace Y only,
+			// across the port range 80-82 via endPort. Port 79, just below the range,
+			// should stay denied even from Y.
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b", "y/a", "z/a")
+			nsX, nsY, nsZ := getK8sNamespaces(k8s)
+
+			allowedLabels := &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					namespaceLabelKey: nsY,
+				},
+			}
+			endPort := int32(82)
+			ingressRule := networkingv1.NetworkPolicyIngressRule{}
+			ingressRule.From = append(ingressRule.From, networkingv1.NetworkPolicyPeer{NamespaceSelector: allowedLabels})
+			ingressRule.Ports = append(ingressRule.Ports, networkingv1.NetworkPolicyPort{Port: &intstr.IntOrString{IntVal: 80}, EndPort: &endPort, Protocol: &protocolUDP})
+			allowPortRangePolicy := GenNetworkPolicyWithNameAndPodMatchLabel("allow-client-a-port-range-ns-y", map[string]string{"pod": "a"}, SetSpecIngressRules(ingressRule))
+			CreatePolicy(ctx, k8s, allowPortRangePolicy, nsX)
+
+			withinRange := NewReachability(k8s.AllPodStrings(), true)
+			withinRange.ExpectPeer(&Peer{Namespace: nsX}, &Peer{Namespace: nsX, Pod: "a"}, false)
+			withinRange.ExpectPeer(&Peer{Namespace: nsY}, &Peer{Namespace: nsX, Pod: "a"}, true)
+			withinRange.ExpectPeer(&Peer{Namespace: nsZ}, &Peer{Namespace: nsX, Pod: "a"}, false)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, EndPort: 82, Protocol: v1.ProtocolUDP, Reachability: withinRange}, OracleModeFromEnv())
+
+			belowRange := NewReachability(k8s.AllPodStrings(), true)
+			belowRange.ExpectAllIngress(NewPodString(nsX, "a"), false)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 79, Protocol: v1.ProtocolUDP, Reachability: belowRange}, OracleModeFromEnv())
+		})
+
+		f.It("should reject a NetworkPolicyPort that combines a named Port with EndPort", feature.NetworkPolicy, func(ctx context.Context) {
+			e2eskipper.SkipUnlessFeatureGateEnabled(features.NetworkPolicyEndPort)
+
+			protocols := []v1.Protocol{protocolUDP}
+			ports := []int32{80}
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			endPort := int32(82)
+			ingressRule := networkingv1.NetworkPolicyIngressRule{}
+			ingressRule.Ports = append(ingressRule.Ports, networkingv1.NetworkPolicyPort{
+				Port:    &intstr.IntOrString{Type: intstr.String, StrVal: "serve-80-udp"},
+				EndPort: &endPort,
+			})
+			policy := GenNetworkPolicyWithNameAndPodMatchLabel("reject-named-port-with-endport-udp", map[string]string{"pod": "a"}, SetSpecIngressRules(ingressRule))
+
+			_, err := k8s.clientSet.NetworkingV1().NetworkPolicies(nsX).Create(ctx, policy, metav1.CreateOptions{})
+			gomega.Expect(err).To(gomega.HaveOccurred(), "the API server must reject a NetworkPolicyPort that sets EndPort alongside a named Port")
 		})
 
 		f.It("should enforce policy to allow traffic only from a pod in a different namespace based on PodSelector and NamespaceSelector", feature.NetworkPolicy, func(ctx context.Context) {
@@ -1385,7 +2093,22 @@ var _ = common.SIGDescribe("Netpol [LinuxOnly]", func() {
 			reachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
 			reachability.Expect(NewPodString(nsY, "a"), NewPodString(nsX, "a"), true)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolUDP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolUDP, Reachability: reachability}, OracleModeFromEnv())
+		})
+
+		f.It("should deny egress to kube-dns and a node's kubelet port by default, then allow each via an explicit rule", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolUDP}
+			ports := []int32{80}
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
+			pod := k8s.model.AllPods()[0]
+
+			const policyName = "deny-egress-for-baselines-udp"
+			denyPolicy := GenNetworkPolicyWithNameAndPodMatchLabel(policyName, map[string]string{"pod": "a"}, SetSpecEgressRules())
+			CreatePolicy(ctx, k8s, denyPolicy, nsX)
+
+			assertKubeDNSEgressDeniedThenAllowed(ctx, k8s, nsX, policyName, pod, v1.ProtocolUDP)
+			assertNodeEgressDeniedThenAllowedViaTightCIDR(ctx, f, k8s, nsX, policyName, pod, v1.ProtocolUDP, dnsEgressRule())
 		})
 	})
 })
@@ -1417,7 +2140,7 @@ var _ = common.SIGDescribe("Netpol", feature.SCTPConnectivity, "[LinuxOnly]", fu
 			reachability := NewReachability(k8s.AllPodStrings(), true)
 			reachability.ExpectPeer(&Peer{}, &Peer{Namespace: nsX}, false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolSCTP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolSCTP, Reachability: reachability}, OracleModeFromEnv())
 		})
 
 		f.It("should enforce policy based on Ports", feature.NetworkPolicy, func(ctx context.Context) {
@@ -1446,7 +2169,61 @@ var _ = common.SIGDescribe("Netpol", feature.SCTPConnectivity, "[LinuxOnly]", fu
 			reachability.ExpectPeer(&Peer{Namespace: nsX}, &Peer{Namespace: nsX, Pod: "a"}, false)
 			reachability.ExpectPeer(&Peer{Namespace: nsZ}, &Peer{Namespace: nsX, Pod: "a"}, false)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolSCTP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 81, Protocol: v1.ProtocolSCTP, Reachability: reachability}, OracleModeFromEnv())
+		})
+
+		f.It("should enforce policy based on Ports with a port range via endPort, combined with a namespaceSelector", feature.NetworkPolicy, func(ctx context.Context) {
+			e2eskipper.SkipUnlessFeatureGateEnabled(features.NetworkPolicyEndPort)
+
+			protocols := []v1.Protocol{protocolSCTP}
+			ports := []int32{79, 80, 81, 82}
+			// SCTP: namespace X should allow ingress to x/a from namespace Y only,
+			// across the port range 80-82 via endPort. Port 79, just below the range,
+			// should stay denied even from Y.
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a", "x/b", "y/a", "z/a")
+			nsX, nsY, nsZ := getK8sNamespaces(k8s)
+
+			allowedLabels := &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					namespaceLabelKey: nsY,
+				},
+			}
+			endPort := int32(82)
+			ingressRule := networkingv1.NetworkPolicyIngressRule{}
+			ingressRule.From = append(ingressRule.From, networkingv1.NetworkPolicyPeer{NamespaceSelector: allowedLabels})
+			ingressRule.Ports = append(ingressRule.Ports, networkingv1.NetworkPolicyPort{Port: &intstr.IntOrString{IntVal: 80}, EndPort: &endPort, Protocol: &protocolSCTP})
+			allowPortRangePolicy := GenNetworkPolicyWithNameAndPodMatchLabel("allow-client-a-port-range-ns-y", map[string]string{"pod": "a"}, SetSpecIngressRules(ingressRule))
+			CreatePolicy(ctx, k8s, allowPortRangePolicy, nsX)
+
+			withinRange := NewReachability(k8s.AllPodStrings(), true)
+			withinRange.ExpectPeer(&Peer{Namespace: nsX}, &Peer{Namespace: nsX, Pod: "a"}, false)
+			withinRange.ExpectPeer(&Peer{Namespace: nsY}, &Peer{Namespace: nsX, Pod: "a"}, true)
+			withinRange.ExpectPeer(&Peer{Namespace: nsZ}, &Peer{Namespace: nsX, Pod: "a"}, false)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, EndPort: 82, Protocol: v1.ProtocolSCTP, Reachability: withinRange}, OracleModeFromEnv())
+
+			belowRange := NewReachability(k8s.AllPodStrings(), true)
+			belowRange.ExpectAllIngress(NewPodString(nsX, "a"), false)
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 79, Protocol: v1.ProtocolSCTP, Reachability: belowRange}, OracleModeFromEnv())
+		})
+
+		f.It("should reject a NetworkPolicyPort that combines a named Port with EndPort", feature.NetworkPolicy, func(ctx context.Context) {
+			e2eskipper.SkipUnlessFeatureGateEnabled(features.NetworkPolicyEndPort)
+
+			protocols := []v1.Protocol{protocolSCTP}
+			ports := []int32{80}
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
+
+			endPort := int32(82)
+			ingressRule := networkingv1.NetworkPolicyIngressRule{}
+			ingressRule.Ports = append(ingressRule.Ports, networkingv1.NetworkPolicyPort{
+				Port:    &intstr.IntOrString{Type: intstr.String, StrVal: "serve-80-sctp"},
+				EndPort: &endPort,
+			})
+			policy := GenNetworkPolicyWithNameAndPodMatchLabel("reject-named-port-with-endport-sctp", map[string]string{"pod": "a"}, SetSpecIngressRules(ingressRule))
+
+			_, err := k8s.clientSet.NetworkingV1().NetworkPolicies(nsX).Create(ctx, policy, metav1.CreateOptions{})
+			gomega.Expect(err).To(gomega.HaveOccurred(), "the API server must reject a NetworkPolicyPort that sets EndPort alongside a named Port")
 		})
 
 		f.It("should enforce policy to allow traffic only from a pod in a different namespace based on PodSelector and NamespaceSelector", feature.NetworkPolicy, func(ctx context.Context) {
@@ -1477,11 +2254,44 @@ var _ = common.SIGDescribe("Netpol", feature.SCTPConnectivity, "[LinuxOnly]", fu
 			reachability.ExpectAllIngress(NewPodString(nsX, "a"), false)
 			reachability.Expect(NewPodString(nsY, "a"), NewPodString(nsX, "a"), true)
 
-			ValidateOrFail(k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolSCTP, Reachability: reachability})
+			ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: 80, Protocol: v1.ProtocolSCTP, Reachability: reachability}, OracleModeFromEnv())
+		})
+
+		f.It("should deny egress to kube-dns and a node's kubelet port by default, then allow each via an explicit rule", feature.NetworkPolicy, func(ctx context.Context) {
+			protocols := []v1.Protocol{protocolSCTP}
+			ports := []int32{80}
+			k8s = initializeResources(ctx, f, protocols, ports, "x/a")
+			nsX, _, _ := getK8sNamespaces(k8s)
+			pod := k8s.model.AllPods()[0]
+
+			const policyName = "deny-egress-for-baselines-sctp"
+			denyPolicy := GenNetworkPolicyWithNameAndPodMatchLabel(policyName, map[string]string{"pod": "a"}, SetSpecEgressRules())
+			CreatePolicy(ctx, k8s, denyPolicy, nsX)
+
+			assertKubeDNSEgressDeniedThenAllowed(ctx, k8s, nsX, policyName, pod, v1.ProtocolSCTP)
+			assertNodeEgressDeniedThenAllowedViaTightCIDR(ctx, f, k8s, nsX, policyName, pod, v1.ProtocolSCTP, dnsEgressRule())
 		})
 	})
 })
 
+// runForProtocols re-runs testFn once per protocol in protocols, each under its own
+// ginkgo.By so a failure names the protocol it occurred under. This lets a single test
+// case exercise TCP, UDP and SCTP variants of the same policy/reachability assertions
+// without duplicating the whole test body per protocol. SCTP is skipped, via
+// skipUnlessProtocolReachable, on clusters whose nodes don't have the SCTP kernel module
+// loaded; since that check itself aborts the spec, SCTP should be listed last so the
+// protocols ahead of it still get to run.
+func runForProtocols(ctx context.Context, k8s *kubeManager, port int32, protocols []v1.Protocol, testFn func(protocol v1.Protocol)) {
+	for _, protocol := range protocols {
+		protocol := protocol
+		ginkgo.By(fmt.Sprintf("repeating for protocol %s", protocol))
+		if protocol == protocolSCTP {
+			skipUnlessProtocolReachable(ctx, k8s, port, protocol)
+		}
+		testFn(protocol)
+	}
+}
+
 // getNamespaceNames returns the set of base namespace names used by this test, taking a root ns as input.
 // The framework will also append a unique suffix when creating the namespaces.
 // This allows tests to run in parallel.
@@ -1573,13 +2383,161 @@ func initializeResources(ctx context.Context, f *framework.Framework, protocols
 	return k8s
 }
 
-// makeLargeCIDRForIP returns a CIDR that matches the given IP and many many many other
-// IPs. (Specifically, it returns the /4 that contains the IP.)
-func makeLargeCIDRForIP(ip string) string {
+// initializeResourcesWithNamespaceLabels is like initializeResources, but additionally
+// stamps nsLabels onto the created namespaces before returning, so tests built around
+// GenNetworkPolicyWithSameNamespaceLabels don't need their own round of
+// AddNamespaceLabel/DeferCleanup calls for labels that are part of the test's topology
+// from the start. nsLabels is keyed by the namespace's short name (x, y, or z; see
+// getK8sNamespaces).
+func initializeResourcesWithNamespaceLabels(ctx context.Context, f *framework.Framework, protocols []v1.Protocol, ports []int32, nsLabels map[string]map[string]string, modelPods ...string) *kubeManager {
+	k8s := initializeResources(ctx, f, protocols, ports, modelPods...)
+	nsX, nsY, nsZ := getK8sNamespaces(k8s)
+	shortToReal := map[string]string{"x": nsX, "y": nsY, "z": nsZ}
+	for short, labels := range nsLabels {
+		ns, ok := shortToReal[short]
+		if !ok {
+			framework.Failf("unknown namespace shorthand %q in initializeResourcesWithNamespaceLabels", short)
+		}
+		for key, value := range labels {
+			ginkgo.DeferCleanup(DeleteNamespaceLabel, k8s, ns, key)
+			AddNamespaceLabel(ctx, k8s, ns, key, value)
+		}
+	}
+	return k8s
+}
+
+// assertDNSAndNodeEgressStillWork checks that pod can still resolve DNS via kube-dns
+// and reach a node-local endpoint, the two allow rules AllowDNSAndNodeEgress and
+// SetSpecEgressRulesWithDNSAndNode carve out of an otherwise egress-denying policy.
+func assertDNSAndNodeEgressStillWork(ctx context.Context, f *framework.Framework, k8s *kubeManager, pod *Pod) {
+	ginkgo.By(fmt.Sprintf("checking that %s/%s can still resolve DNS via kube-dns", pod.Namespace, pod.Name))
+	dnsPods, err := f.ClientSet.CoreV1().Pods(metav1.NamespaceSystem).List(ctx, metav1.ListOptions{LabelSelector: "k8s-app=kube-dns"})
+	framework.ExpectNoError(err, "listing kube-dns pods")
+	if len(dnsPods.Items) == 0 {
+		e2eskipper.Skipf("no kube-dns pods found in namespace %s", metav1.NamespaceSystem)
+	}
+	connected, err := probeAddress(k8s, pod, 80, v1.ProtocolUDP, dnsPods.Items[0].Status.PodIP, 53, v1.ProtocolUDP)
+	framework.ExpectNoError(err, "probing %s/%s -> kube-dns", pod.Namespace, pod.Name)
+	if !connected {
+		framework.Failf("expected %s/%s to still reach kube-dns pod %s/%s on port 53/UDP", pod.Namespace, pod.Name, dnsPods.Items[0].Namespace, dnsPods.Items[0].Name)
+	}
+
+	ginkgo.By(fmt.Sprintf("checking that %s/%s can still reach a node-local endpoint", pod.Namespace, pod.Name))
+	nodes, err := f.ClientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	framework.ExpectNoError(err, "listing nodes")
+	var nodeIP string
+	for _, addr := range nodes.Items[0].Status.Addresses {
+		if addr.Type == v1.NodeInternalIP {
+			nodeIP = addr.Address
+			break
+		}
+	}
+	if nodeIP == "" {
+		framework.Failf("node %s has no InternalIP address", nodes.Items[0].Name)
+	}
+	// The kubelet's secure port is the one endpoint every node reliably has listening,
+	// so it stands in here for "a node-local endpoint".
+	connected, err = probeAddress(k8s, pod, 80, v1.ProtocolUDP, nodeIP, 10250, v1.ProtocolTCP)
+	framework.ExpectNoError(err, "probing %s/%s -> node %s", pod.Namespace, pod.Name, nodeIP)
+	if !connected {
+		framework.Failf("expected %s/%s to still reach node %s on its kubelet port", pod.Namespace, pod.Name, nodeIP)
+	}
+}
+
+// assertKubeDNSEgressDeniedThenAllowed asserts that pod, governed by policyName's
+// default-deny-egress policy, cannot reach kube-system's kube-dns Service IP on port
+// 53/UDP, then updates policyName to add dnsEgressRule and asserts it now can.
+// fromProtocol is pod's own serving protocol (used to pick which of its containers to
+// exec the probe from); the DNS destination itself is always UDP/53. Unlike
+// assertDNSAndNodeEgressStillWork (which probes a live kube-dns pod's own IP),
+// this resolves the synthetic Service ClusterIP clients actually use for DNS lookups.
+func assertKubeDNSEgressDeniedThenAllowed(ctx context.Context, k8s *kubeManager, nsX, policyName string, pod *Pod, fromProtocol v1.Protocol) {
+	dnsClusterIP := kubeDNSServiceClusterIP(ctx, k8s)
+
+	ginkgo.By(fmt.Sprintf("checking that %s/%s is denied egress to kube-dns's Service IP without an explicit allow rule", pod.Namespace, pod.Name))
+	connected, err := probeAddress(k8s, pod, 80, fromProtocol, dnsClusterIP, 53, v1.ProtocolUDP)
+	framework.ExpectNoError(err, "probing %s/%s -> kube-dns Service IP", pod.Namespace, pod.Name)
+	if connected {
+		framework.Failf("expected %s/%s to be denied egress to kube-dns's Service IP before an allow rule is added", pod.Namespace, pod.Name)
+	}
+
+	ginkgo.By("adding a namespaceSelector: kube-system + port-53 egress rule")
+	allowDNSPolicy := GenNetworkPolicyWithNameAndPodMatchLabel(policyName, map[string]string{"pod": pod.Name}, SetSpecEgressRules(dnsEgressRule()))
+	UpdatePolicy(ctx, k8s, allowDNSPolicy, nsX)
+
+	ginkgo.By(fmt.Sprintf("checking that %s/%s can now reach kube-dns's Service IP", pod.Namespace, pod.Name))
+	connected, err = probeAddress(k8s, pod, 80, fromProtocol, dnsClusterIP, 53, v1.ProtocolUDP)
+	framework.ExpectNoError(err, "probing %s/%s -> kube-dns Service IP", pod.Namespace, pod.Name)
+	if !connected {
+		framework.Failf("expected %s/%s to reach kube-dns's Service IP once the allow rule was added", pod.Namespace, pod.Name)
+	}
+}
+
+// assertNodeEgressDeniedThenAllowedViaTightCIDR asserts that pod cannot reach its node's
+// kubelet port, then updates policyName to add a single-node ipBlock allow rule - built
+// via makeTightCIDRForIP rather than makeBroadCIDRForIP's wider range - preserving every
+// rule in keepRules (typically the rules a prior assertion already added, so this
+// doesn't regress what it proved), and asserts the node is now reachable.
+func assertNodeEgressDeniedThenAllowedViaTightCIDR(ctx context.Context, f *framework.Framework, k8s *kubeManager, nsX, policyName string, pod *Pod, protocol v1.Protocol, keepRules ...networkingv1.NetworkPolicyEgressRule) {
+	nodes, err := f.ClientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	framework.ExpectNoError(err, "listing nodes")
+	var nodeIP string
+	for _, addr := range nodes.Items[0].Status.Addresses {
+		if addr.Type == v1.NodeInternalIP {
+			nodeIP = addr.Address
+			break
+		}
+	}
+	if nodeIP == "" {
+		framework.Failf("node %s has no InternalIP address", nodes.Items[0].Name)
+	}
+
+	ginkgo.By(fmt.Sprintf("checking that %s/%s is denied egress to node %s's kubelet port without an explicit allow rule", pod.Namespace, pod.Name, nodeIP))
+	connected, err := probeAddress(k8s, pod, 80, protocol, nodeIP, 10250, v1.ProtocolTCP)
+	framework.ExpectNoError(err, "probing %s/%s -> node %s", pod.Namespace, pod.Name, nodeIP)
+	if connected {
+		framework.Failf("expected %s/%s to be denied egress to node %s before an allow rule is added", pod.Namespace, pod.Name, nodeIP)
+	}
+
+	ginkgo.By(fmt.Sprintf("adding a tight CIDR allow rule for node %s", nodeIP))
+	nodeRule := networkingv1.NetworkPolicyEgressRule{
+		To:    []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: makeTightCIDRForIP(nodeIP)}}},
+		Ports: []networkingv1.NetworkPolicyPort{{Protocol: &protocolTCP, Port: &intstr.IntOrString{IntVal: 10250}}},
+	}
+	rules := append(append([]networkingv1.NetworkPolicyEgressRule{}, keepRules...), nodeRule)
+	allowNodePolicy := GenNetworkPolicyWithNameAndPodMatchLabel(policyName, map[string]string{"pod": pod.Name}, SetSpecEgressRules(rules...))
+	UpdatePolicy(ctx, k8s, allowNodePolicy, nsX)
+
+	ginkgo.By(fmt.Sprintf("checking that %s/%s can now reach node %s's kubelet port", pod.Namespace, pod.Name, nodeIP))
+	connected, err = probeAddress(k8s, pod, 80, protocol, nodeIP, 10250, v1.ProtocolTCP)
+	framework.ExpectNoError(err, "probing %s/%s -> node %s", pod.Namespace, pod.Name, nodeIP)
+	if !connected {
+		framework.Failf("expected %s/%s to reach node %s once the tight-CIDR allow rule was added", pod.Namespace, pod.Name, nodeIP)
+	}
+}
+
+// makeBroadCIDRForIP returns a meaningfully large but non-trivial CIDR containing ip:
+// a /8 for IPv4, a /64 for IPv6. Unlike a single-host CIDR (see makeTightCIDRForIP),
+// this is for "allow a wide range, then except this one address" cases, where the range
+// still needs to be narrow enough that a test can assert other addresses in it aren't
+// reachable by coincidence.
+func makeBroadCIDRForIP(ip string) string {
+	podIP := utilnet.ParseIPSloppy(ip)
+	if ip4 := podIP.To4(); ip4 != nil {
+		cidrBase := ip4.Mask(net.CIDRMask(8, 32))
+		return fmt.Sprintf("%s/8", cidrBase.String())
+	}
+	cidrBase := podIP.Mask(net.CIDRMask(64, 128))
+	return fmt.Sprintf("%s/64", cidrBase.String())
+}
+
+// makeTightCIDRForIP is makeBroadCIDRForIP's opposite: it returns the smallest CIDR that
+// matches exactly ip (a /32 for IPv4, a /128 for IPv6), for an ipBlock peer meant to
+// allow only that one address - e.g. a single node's IP - rather than a broad range.
+func makeTightCIDRForIP(ip string) string {
 	podIP := utilnet.ParseIPSloppy(ip)
 	if ip4 := podIP.To4(); ip4 != nil {
-		podIP = ip4
+		return fmt.Sprintf("%s/32", ip4.String())
 	}
-	cidrBase := podIP.Mask(net.CIDRMask(4, 8*len(podIP)))
-	return fmt.Sprintf("%s/4", cidrBase.String())
+	return fmt.Sprintf("%s/128", podIP.String())
 }
@@ -0,0 +1,273 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netpol
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/test/e2e/framework"
+	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+)
+
+// OracleMode selects which control-plane inspection ValidateOrFailWithOracle performs,
+// on top of the ordinary data-plane probe, before declaring a TestCase's expectations
+// met. It exists because a data-plane probe alone can't distinguish "the CNI compiled
+// this policy into the dataplane correctly" from "the CNI happens to allow/deny this
+// traffic for an unrelated reason" (e.g. a stale chain nobody is evaluating, or a
+// default-allow fallback masking a missing rule).
+type OracleMode string
+
+const (
+	// OracleModeNone runs no oracle check; ValidateOrFailWithOracle behaves exactly like
+	// ValidateOrFail.
+	OracleModeNone OracleMode = ""
+	// OracleModeIPTables inspects nft/iptables-save/ipset state on every node, looking
+	// for the KUBE-POD-FW-*/KUBE-NWPLCY-*/KUBE-SRC-*/KUBE-DST-* chains and ipsets the
+	// kube-router-derived netpol controller (see k3s's pkg/agent/netpol) materializes
+	// NetworkPolicies into.
+	OracleModeIPTables OracleMode = "iptables"
+)
+
+// netpolOracleModeEnv is the environment variable ValidateOrFailWithOracle's caller is
+// expected to read via OracleModeFromEnv to decide whether to run the oracle step.
+const netpolOracleModeEnv = "NETPOL_ORACLE_MODE"
+
+// OracleModeFromEnv returns the OracleMode requested via the NETPOL_ORACLE_MODE
+// environment variable, or OracleModeNone if it's unset or holds an unrecognized value.
+func OracleModeFromEnv() OracleMode {
+	switch mode := OracleMode(os.Getenv(netpolOracleModeEnv)); mode {
+	case OracleModeIPTables:
+		return mode
+	default:
+		return OracleModeNone
+	}
+}
+
+// ValidateOrFailWithOracle is ValidateOrFail plus, when mode is not OracleModeNone, a
+// control-plane oracle check that runs first: it inspects every node's iptables/ipset
+// state for rules that mention the model's pod IPs and fails the test immediately, with a
+// diagnosis of what's missing, rather than leaving a CNI bug that happens to produce the
+// right data-plane outcome for the wrong reason to surface (or not) only via the probe.
+func ValidateOrFailWithOracle(ctx context.Context, k8s *kubeManager, tc *TestCase, mode OracleMode) {
+	if mode == OracleModeIPTables {
+		framework.ExpectNoError(runIPTablesOracle(ctx, k8s, tc), "control-plane oracle check for port %d/%s", tc.ToPort, tc.Protocol)
+	}
+	ValidateOrFail(k8s, tc)
+}
+
+// VerifyOracleCleanupOrFail is runIPTablesOracle's part (c): once a caller has torn
+// policies down (typically via k8s.cleanNetworkPolicies), it re-inspects every node's
+// iptables/ipset state and fails if any rule line still mentions one of the model's pod
+// IPs, i.e. the CNI left a stale rule behind instead of actually retracting the policy. A
+// no-op when mode is OracleModeNone.
+func VerifyOracleCleanupOrFail(ctx context.Context, k8s *kubeManager, tc *TestCase, mode OracleMode) {
+	if mode == OracleModeIPTables {
+		framework.ExpectNoError(verifyNoResidualChains(ctx, k8s, tc), "control-plane oracle residual-chain check for port %d/%s", tc.ToPort, tc.Protocol)
+	}
+}
+
+// oracleDebugNamespace is the namespace runIPTablesOracle's privileged, host-network
+// debug pods live in - distinct from NodeProbeNamespace's pods, since those serve
+// netexec traffic rather than running host-side inspection commands.
+const oracleDebugNamespace = "netpol-oracle"
+
+// runIPTablesOracle execs, on every node, `nft list ruleset` (falling back to
+// `iptables-save` and `ipset save` for nft-less nodes) via a privileged host-network
+// debug pod, and asserts that (a) at least one line of the dump mentions the IP of one
+// of the model's pods (every pod in k8s.model, not only those tc.Reachability expects to
+// be affected), and (b) among those lines, at least one names tc's own port/protocol
+// tuple. Part (c), that no such line remains once the policy is torn down, is
+// verifyNoResidualChains's job: callers reach it through VerifyOracleCleanupOrFail,
+// after cleanup.
+func runIPTablesOracle(ctx context.Context, k8s *kubeManager, tc *TestCase) error {
+	nodes, err := k8s.clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing nodes for iptables oracle: %w", err)
+	}
+
+	podIPs, err := targetPodIPs(ctx, k8s)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, node := range nodes.Items {
+		ruleset, err := execOracleDebugPod(ctx, k8s, node.Name)
+		if err != nil {
+			return fmt.Errorf("inspecting node %s's firewall state: %w", node.Name, err)
+		}
+		matched := chainsReferencingAny(ruleset, podIPs)
+		switch {
+		case len(matched) == 0:
+			missing = append(missing, fmt.Sprintf("%s (no chain references a target pod IP)", node.Name))
+		case len(chainsAuthorizingPortProto(matched, tc)) == 0:
+			missing = append(missing, fmt.Sprintf("%s (matching chain(s) found but none authorize port %d/%s)", node.Name, tc.ToPort, tc.Protocol))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("oracle check failed for %d target pod IP(s) on node(s): %s", len(podIPs), strings.Join(missing, "; "))
+	}
+	return nil
+}
+
+// verifyNoResidualChains is runIPTablesOracle's part (c): it execs the same node
+// inspection but fails if any rule line still mentions one of the model's pod IPs, i.e. the
+// CNI left the policy's rules in place after it was supposed to have been retracted.
+func verifyNoResidualChains(ctx context.Context, k8s *kubeManager, tc *TestCase) error {
+	nodes, err := k8s.clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("listing nodes for iptables oracle: %w", err)
+	}
+
+	podIPs, err := targetPodIPs(ctx, k8s)
+	if err != nil {
+		return err
+	}
+
+	var residual []string
+	for _, node := range nodes.Items {
+		ruleset, err := execOracleDebugPod(ctx, k8s, node.Name)
+		if err != nil {
+			return fmt.Errorf("inspecting node %s's firewall state: %w", node.Name, err)
+		}
+		if matched := chainsReferencingAny(ruleset, podIPs); len(matched) > 0 {
+			residual = append(residual, node.Name)
+		}
+	}
+	if len(residual) > 0 {
+		return fmt.Errorf("residual chain(s) still reference a target pod IP on node(s) %s after cleanup", strings.Join(residual, ", "))
+	}
+	return nil
+}
+
+// targetPodIPs fetches the live PodIP of every pod k8s.model describes, for
+// chainsReferencingAny to match against.
+func targetPodIPs(ctx context.Context, k8s *kubeManager) (map[string]PodString, error) {
+	pods := k8s.model.AllPods()
+	podIPs := make(map[string]PodString, len(pods))
+	for _, pod := range pods {
+		podObj, err := k8s.clientSet.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("getting pod %s/%s for iptables oracle: %w", pod.Namespace, pod.Name, err)
+		}
+		if podObj.Status.PodIP != "" {
+			podIPs[podObj.Status.PodIP] = pod.PodString()
+		}
+	}
+	return podIPs, nil
+}
+
+// chainsReferencingAny returns the subset of ruleset's lines that mention one of
+// podIPs's keys, as a crude but dependency-free stand-in for actually parsing nft/
+// iptables-save grammar: good enough to tell "some chain was materialized for this pod"
+// from "nothing was", which is the oracle's job.
+func chainsReferencingAny(ruleset string, podIPs map[string]PodString) []string {
+	var matched []string
+	for _, line := range strings.Split(ruleset, "\n") {
+		for ip := range podIPs {
+			if strings.Contains(line, ip) {
+				matched = append(matched, line)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// chainsAuthorizingPortProto narrows matched (pod-IP-referencing) lines down to those
+// that also authorize tc's port/protocol tuple, recognizing both the "dpt:<port>"
+// rendering of `iptables -L` and the "dport <port>" form found in nft output (and inside
+// iptables-save's "--dport <port>"), alongside a same-line protocol keyword
+// (tcp/udp/sctp).
+func chainsAuthorizingPortProto(matched []string, tc *TestCase) []string {
+	proto := strings.ToLower(string(tc.Protocol))
+	dptToken := fmt.Sprintf("dpt:%d", tc.ToPort)
+	dportToken := fmt.Sprintf("dport %d", tc.ToPort)
+
+	var authorizing []string
+	for _, line := range matched {
+		lower := strings.ToLower(line)
+		if !strings.Contains(lower, proto) {
+			continue
+		}
+		if strings.Contains(line, dptToken) || strings.Contains(line, dportToken) {
+			authorizing = append(authorizing, line)
+		}
+	}
+	return authorizing
+}
+
+// execOracleDebugPod ensures a privileged, host-network, host-PID debug pod exists on
+// nodeName, then execs into it to dump the node's firewall state: `nft list ruleset`, or
+// if that fails (no nft binary), `iptables-save` followed by `ipset save`.
+func execOracleDebugPod(ctx context.Context, k8s *kubeManager, nodeName string) (string, error) {
+	podName := fmt.Sprintf("oracle-%s", nodeName)
+	if _, err := k8s.clientSet.CoreV1().Namespaces().Get(ctx, oracleDebugNamespace, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+		if _, err := k8s.clientSet.CoreV1().Namespaces().Create(ctx, &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: oracleDebugNamespace},
+		}, metav1.CreateOptions{}); err != nil {
+			return "", fmt.Errorf("unable to create namespace %s: %w", oracleDebugNamespace, err)
+		}
+	} else if err != nil {
+		return "", fmt.Errorf("unable to get namespace %s: %w", oracleDebugNamespace, err)
+	}
+
+	privileged := true
+	_, err := k8s.clientSet.CoreV1().Pods(oracleDebugNamespace).Create(ctx, &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: oracleDebugNamespace},
+		Spec: v1.PodSpec{
+			HostNetwork: true,
+			HostPID:     true,
+			NodeName:    nodeName,
+			Tolerations: controlPlaneTolerations(),
+			Containers: []v1.Container{{
+				Name:            "oracle",
+				Image:           imageutils.GetE2EImage(imageutils.Agnhost),
+				Command:         []string{"sleep", "infinity"},
+				ImagePullPolicy: v1.PullIfNotPresent,
+				SecurityContext: &v1.SecurityContext{Privileged: &privileged},
+			}},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("creating oracle debug pod on node %s: %w", nodeName, err)
+	}
+	if err := e2epod.WaitForPodRunningInNamespace(ctx, k8s.clientSet, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: oracleDebugNamespace}}); err != nil {
+		return "", fmt.Errorf("oracle debug pod on node %s did not become ready: %w", nodeName, err)
+	}
+
+	if out, _, err := e2epod.ExecCommandInContainerWithFullOutput(k8s.framework, podName, "oracle", "nsenter", "-t", "1", "-n", "nft", "list", "ruleset"); err == nil {
+		return out, nil
+	}
+
+	iptablesOut, _, err := e2epod.ExecCommandInContainerWithFullOutput(k8s.framework, podName, "oracle", "nsenter", "-t", "1", "-n", "iptables-save")
+	if err != nil {
+		return "", fmt.Errorf("neither nft nor iptables-save succeeded on node %s: %w", nodeName, err)
+	}
+	ipsetOut, _, err := e2epod.ExecCommandInContainerWithFullOutput(k8s.framework, podName, "oracle", "nsenter", "-t", "1", "-n", "ipset", "save")
+	if err != nil {
+		return "", fmt.Errorf("ipset save failed on node %s: %w", nodeName, err)
+	}
+	return iptablesOut + "\n" + ipsetOut, nil
+}
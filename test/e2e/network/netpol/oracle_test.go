@@ -0,0 +1,76 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netpol
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestChainsReferencingAny(t *testing.T) {
+	podIPs := map[string]PodString{
+		"10.0.0.1": "x/a",
+		"10.0.0.2": "x/b",
+	}
+	ruleset := "-A KUBE-NWPLCY-abc -s 10.0.0.1/32 -j KUBE-POD-FW-xyz\n" +
+		"-A KUBE-SRC-foo -d 10.0.0.3/32 -j ACCEPT\n"
+
+	matched := chainsReferencingAny(ruleset, podIPs)
+	if len(matched) != 1 {
+		t.Fatalf("got %d matched line(s), want 1: %v", len(matched), matched)
+	}
+}
+
+func TestChainsAuthorizingPortProto(t *testing.T) {
+	tc := &TestCase{ToPort: 80, Protocol: v1.ProtocolTCP}
+
+	cases := []struct {
+		name    string
+		matched []string
+		want    int
+	}{
+		{
+			name:    "iptables -L dpt rendering matches",
+			matched: []string{"ACCEPT tcp -- anywhere 10.0.0.1 tcp dpt:80"},
+			want:    1,
+		},
+		{
+			name:    "nft dport rendering matches",
+			matched: []string{"tcp dport 80 ip saddr 10.0.0.1 accept"},
+			want:    1,
+		},
+		{
+			name:    "wrong port does not match",
+			matched: []string{"tcp dport 81 ip saddr 10.0.0.1 accept"},
+			want:    0,
+		},
+		{
+			name:    "wrong protocol does not match",
+			matched: []string{"udp dport 80 ip saddr 10.0.0.1 accept"},
+			want:    0,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := chainsAuthorizingPortProto(c.matched, tc)
+			if len(got) != c.want {
+				t.Errorf("got %d authorizing line(s), want %d: %v", len(got), c.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netpol
+
+import (
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// NetworkPolicySpecMutator customizes a NetworkPolicySpec being built by
+// GenNetworkPolicyWithNameAndPodSelector. SetSpecIngressRules and SetSpecEgressRules
+// are the two mutators tests use.
+type NetworkPolicySpecMutator func(spec *networkingv1.NetworkPolicySpec)
+
+// GenNetworkPolicyWithNameAndPodSelector builds a NetworkPolicy named name, selecting
+// pods via podSelector, and applies every mutator to its spec in order.
+func GenNetworkPolicyWithNameAndPodSelector(name string, podSelector metav1.LabelSelector, mutators ...NetworkPolicySpecMutator) *networkingv1.NetworkPolicy {
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: podSelector,
+		},
+	}
+	for _, mutate := range mutators {
+		mutate(&policy.Spec)
+	}
+	return policy
+}
+
+// GenNetworkPolicyWithNameAndPodMatchLabel is a convenience wrapper around
+// GenNetworkPolicyWithNameAndPodSelector for the common case of a plain MatchLabels
+// pod selector.
+func GenNetworkPolicyWithNameAndPodMatchLabel(name string, matchLabels map[string]string, mutators ...NetworkPolicySpecMutator) *networkingv1.NetworkPolicy {
+	return GenNetworkPolicyWithNameAndPodSelector(name, metav1.LabelSelector{MatchLabels: matchLabels}, mutators...)
+}
+
+// SetSpecIngressRules sets the policy's ingress rules and marks it as governing
+// Ingress. Called with no rules, it produces a non-nil empty ingress rule list, i.e. a
+// "deny all ingress" policy.
+func SetSpecIngressRules(rules ...networkingv1.NetworkPolicyIngressRule) NetworkPolicySpecMutator {
+	return func(spec *networkingv1.NetworkPolicySpec) {
+		if rules == nil {
+			rules = []networkingv1.NetworkPolicyIngressRule{}
+		}
+		spec.Ingress = rules
+		addPolicyType(spec, networkingv1.PolicyTypeIngress)
+	}
+}
+
+// SetSpecEgressRules sets the policy's egress rules and marks it as governing Egress.
+// Called with no rules, it produces a non-nil empty egress rule list, i.e. a
+// "deny all egress" policy.
+func SetSpecEgressRules(rules ...networkingv1.NetworkPolicyEgressRule) NetworkPolicySpecMutator {
+	return func(spec *networkingv1.NetworkPolicySpec) {
+		if rules == nil {
+			rules = []networkingv1.NetworkPolicyEgressRule{}
+		}
+		spec.Egress = rules
+		addPolicyType(spec, networkingv1.PolicyTypeEgress)
+	}
+}
+
+// OmitPolicyTypes clears any PolicyTypes recorded by earlier mutators, producing a
+// NetworkPolicy that leaves Spec.PolicyTypes empty. Many user-authored manifests omit
+// PolicyTypes and rely on the API server/CNI inferring Ingress from a non-nil
+// Spec.Ingress and Egress from a non-nil Spec.Egress (or from the presence of
+// Spec.Egress alone), so it must be the last mutator passed to
+// GenNetworkPolicyWithNameAndPodSelector.
+func OmitPolicyTypes() NetworkPolicySpecMutator {
+	return func(spec *networkingv1.NetworkPolicySpec) {
+		spec.PolicyTypes = nil
+	}
+}
+
+// SetSpecPolicyTypesUnset is an alias for OmitPolicyTypes, named to match the
+// PolicyTypes-inference test matrix (see network_policy.go) that exercises every
+// combination of Ingress/Egress rules with PolicyTypes left unset.
+func SetSpecPolicyTypesUnset() NetworkPolicySpecMutator {
+	return OmitPolicyTypes()
+}
+
+// SetSpecIngressRulesWithSameNamespaceLabels sets an ingress rule allowing traffic only
+// from namespaces whose values for every one of keys equal ownNamespaceLabels' values
+// for those same keys, approximating a "sameLabels" namespace peer (see Peer.SameLabels
+// and the CNI-specific extensions, e.g. Antrea's ClusterNetworkPolicy, it mirrors) using
+// only core NetworkPolicy fields. Core NetworkPolicy has no "equal to my own namespace's
+// label" concept, so this resolves the concrete values up front from
+// ownNamespaceLabels (typically the policy's own namespace's current labels, as fetched
+// by kubeManager.NamespaceLabels) and bakes them into a static NamespaceSelector; any
+// namespace sharing that same combination of values, now or after being relabeled to
+// match, satisfies the rule.
+func SetSpecIngressRulesWithSameNamespaceLabels(ownNamespaceLabels map[string]string, keys ...string) NetworkPolicySpecMutator {
+	matchLabels := make(map[string]string, len(keys))
+	for _, key := range keys {
+		matchLabels[key] = ownNamespaceLabels[key]
+	}
+	rule := networkingv1.NetworkPolicyIngressRule{
+		From: []networkingv1.NetworkPolicyPeer{{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: matchLabels},
+		}},
+	}
+	return SetSpecIngressRules(rule)
+}
+
+// GenNetworkPolicyWithSameNamespaceLabels builds a NetworkPolicy selecting pods by
+// podMatchLabels and allowing ingress only from namespaces that share
+// ownNamespaceLabels' values for every one of keys (see
+// SetSpecIngressRulesWithSameNamespaceLabels). This is the "SameLabels"/tenancy-
+// isolation shape: ownNamespaceLabels is typically the policy's own namespace's
+// current labels, as fetched via kubeManager.NamespaceLabels before calling this.
+func GenNetworkPolicyWithSameNamespaceLabels(name string, podMatchLabels map[string]string, ownNamespaceLabels map[string]string, keys ...string) *networkingv1.NetworkPolicy {
+	return GenNetworkPolicyWithNameAndPodMatchLabel(name, podMatchLabels, SetSpecIngressRulesWithSameNamespaceLabels(ownNamespaceLabels, keys...))
+}
+
+// SetSpecIngressRulesWithPortRange sets a single ingress rule, with no peer
+// restriction, allowing proto traffic on every port from start through end (inclusive)
+// via a NetworkPolicyPort{Port: start, EndPort: end}. It's the port-range counterpart of
+// passing a single-port NetworkPolicyPort to SetSpecIngressRules, for tests that only
+// care about the range itself and not any accompanying peer selector.
+func SetSpecIngressRulesWithPortRange(start, end int32, proto v1.Protocol) NetworkPolicySpecMutator {
+	rule := networkingv1.NetworkPolicyIngressRule{
+		Ports: []networkingv1.NetworkPolicyPort{{
+			Port:     &intstr.IntOrString{IntVal: start},
+			EndPort:  &end,
+			Protocol: &proto,
+		}},
+	}
+	return SetSpecIngressRules(rule)
+}
+
+func addPolicyType(spec *networkingv1.NetworkPolicySpec, policyType networkingv1.PolicyType) {
+	for _, existing := range spec.PolicyTypes {
+		if existing == policyType {
+			return
+		}
+	}
+	spec.PolicyTypes = append(spec.PolicyTypes, policyType)
+}
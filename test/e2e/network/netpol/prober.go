@@ -0,0 +1,140 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netpol
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/onsi/ginkgo/v2"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const defaultProbeWorkers = 5
+
+var probeWorkers = flag.Int("probe-workers", defaultProbeWorkers,
+	"maximum number of source pods Reachability.Probe probes concurrently; each worker sweeps all of its source pod's destinations in turn")
+
+// ProberOpts configures Reachability.Probe. A zero value uses --probe-workers worth of
+// concurrency and a 5s per-probe timeout.
+type ProberOpts struct {
+	// Workers caps how many source pods are probed concurrently. Each worker sweeps
+	// every destination for the one source pod it's handling, one probe at a time, so
+	// this is also the concurrency a single TestCase's probing adds to the cluster. A
+	// value <= 0 uses the --probe-workers flag.
+	Workers int
+	// Timeout bounds a single (src, dst) probe. A value <= 0 uses 5 seconds.
+	Timeout time.Duration
+}
+
+// Probe fans the NxN grid of r's pods out over a bounded worker pool, one worker per
+// concurrently-probed source pod (see ProberOpts.Workers), instead of the single
+// goroutine walking every (from, to) pair serially. Results are recorded into
+// r.Observed the same way validatePortOrErr's old inline loop did, and the resulting
+// Observed Matrix (see Reachability.Summary) is returned directly for callers that
+// want the truth table without going through ValidateOrFail's pass/fail check.
+//
+// Each probe still execs into its source pod individually; this does not run a
+// long-lived prober sidecar with its own HTTP control plane to batch a source's probes
+// onto one connection, since that needs a purpose-built test image this tree has no
+// way to build or publish. Workers is the speedup lever in the meantime: it turns the
+// probing from O(pods) sequential passes into O(pods/Workers).
+func (r *Reachability) Probe(ctx context.Context, k8s *kubeManager, port int, protocol v1.Protocol, opts ProberOpts) (Matrix, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = *probeWorkers
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	pods := k8s.model.AllPods()
+	sources := make(chan *Pod, len(pods))
+	for _, from := range pods {
+		sources <- from
+	}
+	close(sources)
+
+	var mu sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for from := range sources {
+				for _, to := range pods {
+					probeCtx, cancel := context.WithTimeout(ctx, timeout)
+					connected, err := probeConnectivity(probeCtx, k8s, from, to, port, protocol)
+					cancel()
+
+					mu.Lock()
+					if err != nil {
+						if firstErr == nil {
+							firstErr = fmt.Errorf("unable to probe %s -> %s on port %d: %w", from.PodString(), to.PodString(), port, err)
+						}
+					} else {
+						r.Observe(from.PodString(), to.PodString(), connected)
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	_, observed, _, _ := r.Summary()
+	return observed, nil
+}
+
+// TruthTable is r.Summary's three matrices packaged as a single value, so a ProbeAll
+// caller gets one result to log, diff, or attach to the test report instead of having to
+// thread Expected/Observed/Diff/wrong through separately.
+type TruthTable struct {
+	Expected Matrix `json:"expected"`
+	Observed Matrix `json:"observed"`
+	Diff     Matrix `json:"diff"`
+	Wrong    int    `json:"wrong"`
+}
+
+// ProbeAll runs r.Probe for port/protocol, then packages the resulting truth table as a
+// TruthTable and, via ginkgo.AddReportEntry, attaches it to the running spec's report as
+// JSON - a machine-readable artifact a CI harness can pull off a failed run without
+// re-parsing the pretty-printed PrintSummary output. It's the entry point ValidateOrFail
+// is built on; call it directly when a test wants the full truth table (e.g. to assert on
+// specific cells) rather than just a pass/fail.
+func (r *Reachability) ProbeAll(ctx context.Context, k8s *kubeManager, port int, protocol v1.Protocol, opts ProberOpts) (*TruthTable, error) {
+	if _, err := r.Probe(ctx, k8s, port, protocol, opts); err != nil {
+		return nil, err
+	}
+	expected, observed, diff, wrong := r.Summary()
+	tt := &TruthTable{Expected: expected, Observed: observed, Diff: diff, Wrong: wrong}
+
+	if raw, err := json.Marshal(tt); err == nil {
+		ginkgo.AddReportEntry(fmt.Sprintf("netpol-truth-table-port-%d-%s", port, protocol), string(raw))
+	}
+	return tt, nil
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netpol
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Reachability represents the expected (and, once ValidateOrFail has run, the
+// observed) connectivity truth table between every pair of pods considered by a test:
+// Expected[from][to] is true if a connection from pod "from" to pod "to" should
+// succeed.
+type Reachability struct {
+	Expected map[PodString]map[PodString]bool
+	Observed map[PodString]map[PodString]bool
+
+	podLabels map[PodString]map[string]string
+	nsLabels  map[string]map[string]string
+}
+
+// NewReachability builds a Reachability truth table over every pod in pods,
+// initialized so that every (from, to) pair is set to defaultExpectation. When the
+// package's ignoreLoopback is set, it then calls AllowLoopback itself, so every pod's
+// connection to itself is expected to succeed.
+func NewReachability(pods []PodString, defaultExpectation bool) *Reachability {
+	r := &Reachability{
+		Expected:  map[PodString]map[PodString]bool{},
+		Observed:  map[PodString]map[PodString]bool{},
+		podLabels: map[PodString]map[string]string{},
+		nsLabels:  map[string]map[string]string{},
+	}
+	for _, from := range pods {
+		r.podLabels[from] = map[string]string{"pod": from.PodName()}
+		r.nsLabels[from.Namespace()] = map[string]string{namespaceLabelKey: from.Namespace()}
+		r.Expected[from] = map[PodString]bool{}
+		r.Observed[from] = map[PodString]bool{}
+		for _, to := range pods {
+			r.Expected[from][to] = defaultExpectation
+		}
+	}
+	if ignoreLoopback {
+		r.AllowLoopback()
+	}
+	return r
+}
+
+// SetNamespaceLabels tells the Reachability about the current, live labels of every
+// namespace under test, so that ExpectPeer can resolve Peer.SameLabels and
+// Peer.NamespaceSelector against up-to-date state rather than only the namespace's
+// name. Tests that use SameLabels peers should call this (typically using labels
+// fetched via kubeManager's namespace lister) before calling ExpectPeer.
+func (r *Reachability) SetNamespaceLabels(nsLabels map[string]map[string]string) {
+	for ns, labels := range nsLabels {
+		r.nsLabels[ns] = labels
+	}
+}
+
+// AllowLoopback sets every pod's connection to itself to true, matching the netpol
+// suite's policy of ignoring loopback behavior when judging pass/fail (see
+// ignoreLoopback in network_policy.go).
+func (r *Reachability) AllowLoopback() {
+	for pod := range r.Expected {
+		r.Expected[pod][pod] = true
+	}
+}
+
+// Expect sets the expected connectivity from one specific pod to another.
+func (r *Reachability) Expect(from, to PodString, isConnected bool) {
+	r.Expected[from][to] = isConnected
+}
+
+// ExpectAllIngress sets the expected ingress connectivity for every pod reaching pod,
+// i.e. every Expected[*][pod].
+func (r *Reachability) ExpectAllIngress(pod PodString, connected bool) {
+	for from := range r.Expected {
+		if from == pod && ignoreLoopback {
+			continue
+		}
+		r.Expected[from][pod] = connected
+	}
+}
+
+// ExpectAllEgress sets the expected egress connectivity for pod reaching every other
+// pod, i.e. every Expected[pod][*].
+func (r *Reachability) ExpectAllEgress(pod PodString, connected bool) {
+	for to := range r.Expected[pod] {
+		if to == pod && ignoreLoopback {
+			continue
+		}
+		r.Expected[pod][to] = connected
+	}
+}
+
+// ExpectPeer sets the expected connectivity between every pod matched by from and
+// every pod matched by to. It is typically used right after creating a policy, to
+// describe the isolation/allow rules the policy is expected to enforce. When either
+// Peer uses SameLabels, the reference namespace used to resolve it is the other side's
+// namespace, since a SameLabels peer describes "a namespace whose labels match the
+// namespace this traffic is relative to".
+func (r *Reachability) ExpectPeer(from, to *Peer, connected bool) {
+	for fromPod := range r.Expected {
+		for toPod := range r.Expected[fromPod] {
+			if !from.Matches(fromPod.Namespace(), fromPod.PodName(), r.podLabels[fromPod], r.nsLabels, toRefNamespace(to, toPod)) {
+				continue
+			}
+			if !to.Matches(toPod.Namespace(), toPod.PodName(), r.podLabels[toPod], r.nsLabels, toRefNamespace(from, fromPod)) {
+				continue
+			}
+			r.Expected[fromPod][toPod] = connected
+		}
+	}
+}
+
+// toRefNamespace picks the reference namespace a SameLabels peer should be compared
+// against: a concrete, single-namespace peer's own namespace if it has one, otherwise
+// the pod on the other side of the connection, so that a SameLabels peer with no fixed
+// namespace still has something concrete to compare other namespaces' labels to.
+func toRefNamespace(other *Peer, fallback PodString) string {
+	if other != nil && other.Namespace != "" {
+		return other.Namespace
+	}
+	return fallback.Namespace()
+}
+
+// Observe records the actually-observed connectivity for one pod pair, as found by
+// ValidateOrFail's probing.
+func (r *Reachability) Observe(from, to PodString, isConnected bool) {
+	r.Observed[from][to] = isConnected
+}
+
+// Mismatches returns a human-readable description of every (from, to) pair whose
+// Observed connectivity didn't match Expected, or "" if there were none.
+func (r *Reachability) Mismatches() string {
+	var lines []string
+	for from, tos := range r.Expected {
+		for to, expected := range tos {
+			observed, ok := r.Observed[from][to]
+			if !ok {
+				continue
+			}
+			if observed != expected {
+				lines = append(lines, fmt.Sprintf("  %s -> %s: expected connected=%v, observed connected=%v", from, to, expected, observed))
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "reachability mismatches:\n" + strings.Join(lines, "\n")
+}
+
+// Matrix is an NxN connectivity truth table keyed the same way as
+// Reachability.Expected/Observed, used by Summary/PrintSummary to render a test
+// failure as an aligned ASCII table instead of a flat list of pairs, following the
+// truth-table comparison approach from mattfenwick/cyclonus's pkg/kube/netpol.
+type Matrix map[PodString]map[PodString]bool
+
+// Summary builds the Expected and Observed matrices, plus a Diff matrix that marks
+// only the cells where they disagree, and wrong, the number of such cells. A cell with
+// no recorded Observed value (i.e. never probed) is left unset in Observed and excluded
+// from Diff.
+func (r *Reachability) Summary() (expected, observed, diff Matrix, wrong int) {
+	expected, observed, diff = Matrix{}, Matrix{}, Matrix{}
+	for from, tos := range r.Expected {
+		expected[from] = map[PodString]bool{}
+		observed[from] = map[PodString]bool{}
+		diff[from] = map[PodString]bool{}
+		for to, exp := range tos {
+			expected[from][to] = exp
+			obs, ok := r.Observed[from][to]
+			if !ok {
+				continue
+			}
+			observed[from][to] = obs
+			if obs != exp {
+				diff[from][to] = true
+				wrong++
+			}
+		}
+	}
+	return expected, observed, diff, wrong
+}
+
+// PrintSummary writes the Expected matrix, the Observed matrix, and a Diff matrix
+// (marking only the disagreeing cells), each as an aligned ASCII table with row/column
+// headers like "x/a", followed by a breakdown of wrong cells grouped by
+// (fromNamespace, toNamespace) so an operator can tell at a glance whether a whole
+// namespace pairing is broken versus a single pod.
+func (r *Reachability) PrintSummary(w io.Writer) {
+	expected, observed, diff, wrong := r.Summary()
+	pods := sortedPodStrings(expected)
+
+	fmt.Fprintf(w, "Expected (%d pods):\n", len(pods))
+	printMatrix(w, expected, pods)
+	fmt.Fprintf(w, "\nObserved (%d pods):\n", len(pods))
+	printMatrix(w, observed, pods)
+	fmt.Fprintf(w, "\nDiff (%d wrong cells):\n", wrong)
+	printMatrix(w, diff, pods)
+
+	if wrong == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nWrong cells by namespace pair (from -> to: wrong/total):")
+	type nsPair struct{ from, to string }
+	counts := map[nsPair][2]int{}
+	var order []nsPair
+	for from, tos := range observed {
+		for to := range tos {
+			isWrong := diff[from][to]
+			pair := nsPair{from.Namespace(), to.Namespace()}
+			count, seen := counts[pair]
+			if !seen {
+				order = append(order, pair)
+			}
+			count[1]++
+			if isWrong {
+				count[0]++
+			}
+			counts[pair] = count
+		}
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if order[i].from != order[j].from {
+			return order[i].from < order[j].from
+		}
+		return order[i].to < order[j].to
+	})
+	for _, pair := range order {
+		count := counts[pair]
+		if count[0] == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "  %s -> %s: %d/%d\n", pair.from, pair.to, count[0], count[1])
+	}
+}
+
+// sortedPodStrings returns m's row/column PodStrings (they're the same set) in sorted
+// order, so PrintSummary's tables have a stable, readable layout.
+func sortedPodStrings(m Matrix) []PodString {
+	pods := make([]PodString, 0, len(m))
+	for pod := range m {
+		pods = append(pods, pod)
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i] < pods[j] })
+	return pods
+}
+
+// printMatrix renders m as an aligned ASCII table over pods x pods, using "." for a
+// connected cell and "X" for a blocked one.
+func printMatrix(w io.Writer, m Matrix, pods []PodString) {
+	tw := tabwriter.NewWriter(w, 0, 2, 1, ' ', 0)
+	fmt.Fprint(tw, "\t")
+	for _, to := range pods {
+		fmt.Fprintf(tw, "%s\t", to)
+	}
+	fmt.Fprintln(tw)
+	for _, from := range pods {
+		fmt.Fprintf(tw, "%s\t", from)
+		for _, to := range pods {
+			fmt.Fprintf(tw, "%s\t", cellSymbol(m[from][to]))
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+}
+
+func cellSymbol(connected bool) string {
+	if connected {
+		return "."
+	}
+	return "X"
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netpol
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/onsi/ginkgo/v2"
+
+	"k8s.io/kubernetes/test/e2e/feature"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+// PolicyBuilder builds one NetworkPolicy for a Scenario to create, given the scenario's
+// three namespace names in x/y/z order (see getK8sNamespaces), and names the namespace it
+// should be created in. Most builders only need one or two of nsX/nsY/nsZ; the unused
+// ones are conventionally ignored via _.
+type PolicyBuilder func(nsX, nsY, nsZ string) (policy *networkingv1.NetworkPolicy, ns string)
+
+// Expectation is one (from, to) reachability assertion a Scenario makes after its
+// policies are created, for the given port/protocol. From/To use namespace shorthand the
+// same way PolicyBuilder does: a Peer's Namespace field, if set, should be one of "x",
+// "y", "z" and is resolved against the scenario's real namespace names before being
+// applied to the Reachability (see Scenario.Run).
+type Expectation struct {
+	From, To  *Peer
+	Port      int
+	EndPort   int
+	Protocol  v1.Protocol
+	Connected bool
+}
+
+// Scenario declaratively describes one netpol conformance case: the pod topology to
+// stand up, the policies to create, and the reachability expectations to validate
+// afterward. It exists so that the common initializeResources -> CreatePolicy ->
+// Reachability/ExpectPeer -> ValidateOrFailWithOracle shape most f.It blocks in this
+// package follow can be written as data instead of restating the same sequence of calls,
+// and so that
+// external consumers (e.g. CNI vendors extending this suite) can contribute new cases via
+// a Scenario slice passed to RunScenarios instead of forking network_policy.go.
+type Scenario struct {
+	// Name becomes the f.It description RunScenarios registers this scenario under.
+	Name string
+	// Pods lists the "ns/pod" strings to pass to initializeResources, e.g. "x/a", "y/b".
+	Pods []string
+	// Protocols and Ports configure the servers initializeResources creates on every pod.
+	Protocols []v1.Protocol
+	Ports     []int32
+	// Policies are created, in order, once the pods are up.
+	Policies []PolicyBuilder
+	// Expectations are grouped by (Port, EndPort, Protocol) into one Reachability/
+	// ValidateOrFail call apiece, so a Scenario whose Expectations span multiple ports
+	// still issues one probe pass per port rather than one per Expectation.
+	Expectations []Expectation
+}
+
+// resolveNamespace maps x/y/z namespace shorthand (or a name that isn't shorthand at
+// all) to the scenario's real namespace name; it's shared by Scenario.Run's policy and
+// expectation resolution so both speak the same shorthand.
+func resolveNamespace(ns, nsX, nsY, nsZ string) string {
+	switch ns {
+	case "x":
+		return nsX
+	case "y":
+		return nsY
+	case "z":
+		return nsZ
+	default:
+		return ns
+	}
+}
+
+// resolvePeer returns a copy of p with x/y/z namespace shorthand resolved, or an empty
+// Peer (which matches every pod) if p is nil, so Scenario expectations are allowed to
+// omit From or To.
+func resolvePeer(p *Peer, nsX, nsY, nsZ string) *Peer {
+	if p == nil {
+		return &Peer{}
+	}
+	resolved := *p
+	if resolved.Namespace != "" {
+		resolved.Namespace = resolveNamespace(resolved.Namespace, nsX, nsY, nsZ)
+	}
+	return &resolved
+}
+
+// portProto groups Expectations into the ValidateOrFail calls Scenario.Run issues.
+type portProto struct {
+	port     int
+	endPort  int
+	protocol v1.Protocol
+}
+
+// Run stands up s's pod topology, creates its policies, and validates every expectation,
+// failing the calling spec (via ValidateOrFailWithOracle, the same oracle-checked path
+// every hand-written f.It in this package uses) if any doesn't hold.
+func (s *Scenario) Run(ctx context.Context, f *framework.Framework) {
+	ginkgo.By("initializing resources for scenario " + s.Name)
+	k8s := initializeResources(ctx, f, s.Protocols, s.Ports, s.Pods...)
+	nsX, nsY, nsZ := getK8sNamespaces(k8s)
+
+	for _, build := range s.Policies {
+		policy, ns := build(nsX, nsY, nsZ)
+		CreatePolicy(ctx, k8s, policy, resolveNamespace(ns, nsX, nsY, nsZ))
+	}
+
+	reachabilityByPortProto := map[portProto]*Reachability{}
+	var order []portProto
+	for _, exp := range s.Expectations {
+		key := portProto{exp.Port, exp.EndPort, exp.Protocol}
+		reachability, ok := reachabilityByPortProto[key]
+		if !ok {
+			reachability = NewReachability(k8s.AllPodStrings(), true)
+			reachabilityByPortProto[key] = reachability
+			order = append(order, key)
+		}
+		reachability.ExpectPeer(resolvePeer(exp.From, nsX, nsY, nsZ), resolvePeer(exp.To, nsX, nsY, nsZ), exp.Connected)
+	}
+
+	for _, key := range order {
+		ValidateOrFailWithOracle(ctx, k8s, &TestCase{ToPort: key.port, EndPort: key.endPort, Protocol: key.protocol, Reachability: reachabilityByPortProto[key]}, OracleModeFromEnv())
+	}
+}
+
+// RunScenarios registers one f.It per scenario in scenarios, each running under
+// feature.NetworkPolicy the same way every hand-written case in this package does. This
+// is the entry point a CNI vendor's own Scenario slice goes through to extend the suite
+// without forking network_policy.go.
+func RunScenarios(f *framework.Framework, scenarios []Scenario) {
+	for _, s := range scenarios {
+		s := s
+		f.It(s.Name, feature.NetworkPolicy, func(ctx context.Context) {
+			s.Run(ctx, f)
+		})
+	}
+}
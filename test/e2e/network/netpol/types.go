@@ -0,0 +1,148 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netpol
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/kubernetes/test/e2e/framework"
+)
+
+func labelsSet(m map[string]string) labels.Labels {
+	return labels.Set(m)
+}
+
+// PodString represents a namespace'd pod name, in "namespace/pod" form, and is used as
+// the key into the Reachability truth table.
+type PodString string
+
+// NewPodString constructs a PodString from a namespace and a pod name.
+func NewPodString(namespace string, podName string) PodString {
+	return PodString(fmt.Sprintf("%s/%s", namespace, podName))
+}
+
+// String converts back to a string.
+func (pod PodString) String() string {
+	return string(pod)
+}
+
+// Namespace extracts the namespace.
+func (pod PodString) Namespace() string {
+	ns, _ := pod.split()
+	return ns
+}
+
+// PodName extracts the pod name.
+func (pod PodString) PodName() string {
+	_, podName := pod.split()
+	return podName
+}
+
+func (pod PodString) split() (string, string) {
+	pieces := strings.Split(string(pod), "/")
+	if len(pieces) != 2 {
+		framework.Failf("expected ns/pod, found %+v", pieces)
+	}
+	return pieces[0], pieces[1]
+}
+
+// Peer is used for matching pods by either or both of namespace and pod selection, the
+// same way a NetworkPolicyPeer can select by namespace and/or pod. The zero value
+// (&Peer{}) matches every pod.
+type Peer struct {
+	Namespace         string
+	Pod               string
+	NamespaceSelector *metav1.LabelSelector
+	PodSelector       *metav1.LabelSelector
+	// SameLabels lists namespace label keys that must be present with equal values on
+	// both the reference namespace (the namespace the comparison is anchored to, i.e.
+	// the policy's own namespace) and the candidate namespace for a pod in that
+	// namespace to match this peer. This mirrors the "SameLabels" namespace peer some
+	// CNIs (e.g. Antrea ClusterNetworkPolicy) support in addition to a static
+	// NamespaceSelector: rather than matching a fixed label value, it matches any
+	// namespace whose labels happen to agree with the reference namespace's labels for
+	// the given keys.
+	SameLabels []string
+	// Node restricts this peer to the host-network probe pod for the named node (see
+	// kubeManager.EnsureNodeProbePods), i.e. the pod whose PodString is
+	// "<NodeProbeNamespace>/<Node>". It lets a test write Peer{Node: nodeName} instead of
+	// spelling out Namespace/Pod for what is really just another pod in the model's eyes.
+	Node string
+}
+
+// Matches returns true if ns/podName, with the given pod and namespace labels, is
+// selected by p. nsLabelsByName provides every namespace's labels so that SameLabels
+// comparisons can look up both the candidate namespace's and the reference namespace's
+// labels; refNamespace is the reference namespace that SameLabels compares against
+// (i.e. the namespace the NetworkPolicy containing this peer lives in).
+func (p *Peer) Matches(ns, podName string, podLabels map[string]string, nsLabelsByName map[string]map[string]string, refNamespace string) bool {
+	if p.Namespace != "" && p.Namespace != ns {
+		return false
+	}
+	if p.Pod != "" && p.Pod != podName {
+		return false
+	}
+	if p.Node != "" && (ns != NodeProbeNamespace || podName != p.Node) {
+		return false
+	}
+	if p.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(p.NamespaceSelector)
+		if err != nil {
+			framework.Failf("unable to parse namespace selector: %v", err)
+		}
+		if !selector.Matches(labelsSet(nsLabelsByName[ns])) {
+			return false
+		}
+	}
+	if p.PodSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(p.PodSelector)
+		if err != nil {
+			framework.Failf("unable to parse pod selector: %v", err)
+		}
+		if !selector.Matches(labelsSet(podLabels)) {
+			return false
+		}
+	}
+	if len(p.SameLabels) > 0 {
+		refLabels := nsLabelsByName[refNamespace]
+		candidateLabels := nsLabelsByName[ns]
+		for _, key := range p.SameLabels {
+			refValue, refOK := refLabels[key]
+			candidateValue, candidateOK := candidateLabels[key]
+			if !refOK || !candidateOK || refValue != candidateValue {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// TestCase describes the port/protocol that ValidateOrFail should probe between every
+// pair of pods, together with the Reachability matrix it is expected to observe. If
+// EndPort is non-zero, every port from ToPort through EndPort (inclusive) is probed in
+// turn against the same Reachability, mirroring a NetworkPolicyPort that uses EndPort to
+// describe a port range rather than a single port.
+type TestCase struct {
+	ToPort       int
+	EndPort      int
+	Protocol     v1.Protocol
+	Reachability *Reachability
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+)
+
+// TestPutDirectObjectRoundTrip seeds a namespace directly into the shared test etcd
+// instance and reads it back through the same raw client, the way a test's setup phase
+// would use these helpers to simulate pre-existing cluster state.
+func TestPutDirectObjectRoundTrip(t *testing.T) {
+	etcdOptions := SharedEtcd()
+	client := NewDirectEtcdClient(t, etcdOptions)
+	codec := legacyscheme.Codecs.LegacyCodec(v1.SchemeGroupVersion)
+
+	ns := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "direct-etcd-fixture-test",
+			UID:  types.UID("direct-etcd-fixture-test-uid"),
+		},
+	}
+
+	ctx := context.Background()
+	key := "/namespaces/" + ns.Name
+	PutDirectObject(ctx, t, client, etcdOptions, key, ns, codec)
+
+	resp, err := client.Get(ctx, "/"+etcdOptions.Prefix+key)
+	if err != nil {
+		t.Fatalf("failed to read back object: %v", err)
+	}
+	if len(resp.Kvs) != 1 {
+		t.Fatalf("expected exactly one key at %s, got %d", key, len(resp.Kvs))
+	}
+}
+
+// TestSeedManyWritesEveryItem seeds a batch of namespaces spanning more than one
+// transaction (seedBatchSize) and more than one in-flight batch (seedBatchConcurrency),
+// and checks every one landed in etcd.
+func TestSeedManyWritesEveryItem(t *testing.T) {
+	etcdOptions := SharedEtcd()
+	client := NewDirectEtcdClient(t, etcdOptions)
+	codec := legacyscheme.Codecs.LegacyCodec(v1.SchemeGroupVersion)
+
+	const numNamespaces = seedBatchSize*seedBatchConcurrency + 1
+	items := make([]SeedItem, 0, numNamespaces)
+	for i := 0; i < numNamespaces; i++ {
+		ns := &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("seed-many-fixture-test-%d", i),
+				UID:  types.UID(fmt.Sprintf("seed-many-fixture-test-uid-%d", i)),
+			},
+		}
+		items = append(items, SeedItem{Key: "/namespaces/" + ns.Name, Object: ns, Codec: codec})
+	}
+
+	ctx := context.Background()
+	SeedMany(ctx, t, client, etcdOptions, items)
+
+	resp, err := client.Get(ctx, "/"+etcdOptions.Prefix+"/namespaces/seed-many-fixture-test-", clientv3.WithPrefix())
+	if err != nil {
+		t.Fatalf("failed to read back seeded objects: %v", err)
+	}
+	if len(resp.Kvs) != numNamespaces {
+		t.Fatalf("expected %d seeded namespaces, got %d", numNamespaces, len(resp.Kvs))
+	}
+}
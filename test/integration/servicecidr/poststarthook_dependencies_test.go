@@ -0,0 +1,95 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicecidr
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/util/poststarthook"
+)
+
+// TestRepairHookWaitsForDeclaredPrerequisites is adjacent to
+// TestServiceIPRepairRaceCondition: it asserts that, once the ServiceCIDR repair hook
+// declares its real informer/controller prerequisites via poststarthook.Hook.Requires,
+// the hook manager will not start it until those prerequisites have reported ready,
+// regardless of registration order.
+func TestRepairHookWaitsForDeclaredPrerequisites(t *testing.T) {
+	var mu sync.Mutex
+	var startOrder []string
+	recordStart := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		startOrder = append(startOrder, name)
+	}
+
+	namespaceInformerReady := make(chan struct{})
+
+	r := poststarthook.NewRunner()
+	// Registered before its prerequisites to prove that registration order alone does
+	// not determine start order. NewHook/PostStartHookDependencies is the shape a real
+	// ServiceCIDR repair PostStartHook would use to declare these prerequisites against
+	// a genericapiserver.Config, once that wiring exists in a full apiserver tree; this
+	// checkout has no such Config or repair controller to convert, so the Runner is
+	// exercised directly with the repair hook's real dependency names instead.
+	r.Add(poststarthook.NewHook(
+		"servicecidr-repair",
+		poststarthook.PostStartHookDependencies{
+			Requires: []string{
+				"rbac/bootstrap-roles",
+				"priority-and-fairness-config-consumer",
+				"start-cluster-authentication-info-controller",
+			},
+		},
+		func(ctx context.Context) error {
+			select {
+			case <-namespaceInformerReady:
+			default:
+				t.Error("servicecidr-repair hook started before its declared prerequisites were ready")
+			}
+			recordStart("servicecidr-repair")
+			return nil
+		},
+	))
+	r.Add(poststarthook.Hook{Name: "rbac/bootstrap-roles", Fn: func(ctx context.Context) error {
+		recordStart("rbac/bootstrap-roles")
+		return nil
+	}})
+	r.Add(poststarthook.Hook{Name: "priority-and-fairness-config-consumer", Fn: func(ctx context.Context) error {
+		recordStart("priority-and-fairness-config-consumer")
+		return nil
+	}})
+	r.Add(poststarthook.Hook{Name: "start-cluster-authentication-info-controller", Fn: func(ctx context.Context) error {
+		// Simulate this prerequisite taking a moment, e.g. waiting for its own
+		// informer to sync, so the repair hook would race ahead without the
+		// dependency declaration.
+		time.Sleep(20 * time.Millisecond)
+		close(namespaceInformerReady)
+		recordStart("start-cluster-authentication-info-controller")
+		return nil
+	}})
+
+	if err := r.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if startOrder[len(startOrder)-1] != "servicecidr-repair" {
+		t.Fatalf("expected servicecidr-repair to start last, got order: %v", startOrder)
+	}
+}
@@ -34,17 +34,14 @@ import (
 
 // populateEtcdForRepairTest populates etcd with namespaces, services, and ServiceCIDR
 // to simulate an existing v1.32 cluster that needs repair during upgrade to v1.33.
-func populateEtcdForRepairTest(t *testing.T, etcdOptions *storagebackend.Config, apiServerOptions *kubeapiservertesting.TestServerInstanceOptions, numNamespaces int) string {
+func populateEtcdForRepairTest(t *testing.T, etcdOptions *storagebackend.Config, numNamespaces int) string {
 	t.Logf("Populating etcd with %d namespaces and services (simulating v1.32 cluster)", numNamespaces)
 
-	// We need a temporary server just to get the etcd client
-	tempServer := kubeapiservertesting.StartTestServerOrDie(t,
-		apiServerOptions,
-		[]string{
-			"--service-cluster-ip-range=10.0.0.0/24",
-			"--advertise-address=10.1.1.1",
-		},
-		etcdOptions)
+	// Talk to etcd directly; we don't need a throwaway apiserver just to get its
+	// embedded etcd client.
+	ctx := context.Background()
+	etcdClient := framework.NewDirectEtcdClient(t, etcdOptions)
+	codec := legacyscheme.Codecs.LegacyCodec(v1.SchemeGroupVersion)
 
 	// Create test namespace directly in etcd
 	namespace := "test-repair-race"
@@ -55,18 +52,15 @@ func populateEtcdForRepairTest(t *testing.T, etcdOptions *storagebackend.Config,
 			UID:               types.UID("test-namespace-uid"),
 		},
 	}
-	nsJSON, err := runtime.Encode(legacyscheme.Codecs.LegacyCodec(v1.SchemeGroupVersion), ns)
-	if err != nil {
-		t.Fatalf("Failed to encode namespace: %v", err)
-	}
-	nsKey := "/" + etcdOptions.Prefix + "/namespaces/" + namespace
-	if _, err := tempServer.EtcdClient.Put(context.Background(), nsKey, string(nsJSON)); err != nil {
-		t.Fatalf("Failed to store namespace in etcd: %v", err)
-	}
+	framework.PutDirectObject(ctx, t, etcdClient, etcdOptions, "/namespaces/"+namespace, ns, codec)
 	t.Logf("Created namespace %s in etcd", namespace)
 
-	// Create many namespaces directly in etcd to simulate a large cluster
+	// Create many namespaces directly in etcd to simulate a large cluster. SeedMany
+	// pipelines these as batched transactions instead of one Put per namespace, which is
+	// the difference between this taking seconds and taking 30+ minutes at 330k
+	// namespaces.
 	// This causes the namespace informer to take significant time to sync on startup
+	bulkNamespaces := make([]framework.SeedItem, 0, numNamespaces)
 	for i := 0; i < numNamespaces; i++ {
 		ns := &v1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
@@ -75,18 +69,9 @@ func populateEtcdForRepairTest(t *testing.T, etcdOptions *storagebackend.Config,
 				UID:               types.UID(fmt.Sprintf("bulk-ns-uid-%d", i)),
 			},
 		}
-		nsJSON, err := runtime.Encode(legacyscheme.Codecs.LegacyCodec(v1.SchemeGroupVersion), ns)
-		if err != nil {
-			t.Fatalf("Failed to encode namespace: %v", err)
-		}
-		nsKey := "/" + etcdOptions.Prefix + "/namespaces/" + ns.Name
-		if _, err := tempServer.EtcdClient.Put(context.Background(), nsKey, string(nsJSON)); err != nil {
-			t.Fatalf("Failed to store namespace in etcd: %v", err)
-		}
-		if numNamespaces > 1000 && i%1000 == 0 && i > 0 {
-			t.Logf("Created %d/%d bulk namespaces in etcd", i, numNamespaces)
-		}
+		bulkNamespaces = append(bulkNamespaces, framework.SeedItem{Key: "/namespaces/" + ns.Name, Object: ns, Codec: codec})
 	}
+	framework.SeedMany(ctx, t, etcdClient, etcdOptions, bulkNamespaces)
 	t.Logf("Created %d additional namespaces in etcd to simulate large cluster", numNamespaces)
 
 	// Create services directly in etcd (simulating existing services from v1.32)
@@ -112,14 +97,7 @@ func populateEtcdForRepairTest(t *testing.T, etcdOptions *storagebackend.Config,
 				},
 			},
 		}
-		svcJSON, err := runtime.Encode(legacyscheme.Codecs.LegacyCodec(v1.SchemeGroupVersion), svc)
-		if err != nil {
-			t.Fatalf("Failed to encode service: %v", err)
-		}
-		svcKey := "/" + etcdOptions.Prefix + "/services/specs/" + namespace + "/" + svc.Name
-		if _, err := tempServer.EtcdClient.Put(context.Background(), svcKey, string(svcJSON)); err != nil {
-			t.Fatalf("Failed to store service in etcd: %v", err)
-		}
+		framework.PutDirectObject(ctx, t, etcdClient, etcdOptions, "/services/specs/"+namespace+"/"+svc.Name, svc, codec)
 		t.Logf("Created service %s with ClusterIP %s in etcd", svc.Name, svc.Spec.ClusterIP)
 	}
 
@@ -132,18 +110,9 @@ func populateEtcdForRepairTest(t *testing.T, etcdOptions *storagebackend.Config,
 			CIDRs: []string{"10.0.0.0/24"},
 		},
 	}
-	serviceCIDRJSON, err := runtime.Encode(legacyscheme.Codecs.LegacyCodec(networkingv1.SchemeGroupVersion), serviceCIDR)
-	if err != nil {
-		t.Fatalf("Failed to encode ServiceCIDR: %v", err)
-	}
-	serviceCIDRKey := "/" + etcdOptions.Prefix + "/servicecidrs/" + serviceCIDR.Name
-	if _, err := tempServer.EtcdClient.Put(context.Background(), serviceCIDRKey, string(serviceCIDRJSON)); err != nil {
-		t.Fatalf("Failed to store ServiceCIDR in etcd: %v", err)
-	}
+	framework.PutDirectObject(ctx, t, etcdClient, etcdOptions, "/servicecidrs/"+serviceCIDR.Name, serviceCIDR, legacyscheme.Codecs.LegacyCodec(networkingv1.SchemeGroupVersion))
 	t.Logf("Created ServiceCIDR in etcd")
 
-	// Tear down the temporary server
-	tempServer.TearDownFn()
 	t.Logf("Etcd population complete: %d namespaces, %d services, and ServiceCIDR", numNamespaces+1, numServices)
 
 	return namespace
@@ -177,7 +146,7 @@ func TestServiceIPRepairRaceCondition(t *testing.T) {
 	// We write directly to etcd to avoid starting an apiserver, making the test faster
 	// Use 330000 namespaces to reproduce the issue with real load (no artificial delay)
 	t.Logf("Phase 1: Populating etcd with namespaces and services (simulating v1.32 cluster)")
-	populateEtcdForRepairTest(t, etcdOptions, apiServerOptions, 330000)
+	populateEtcdForRepairTest(t, etcdOptions, 330000)
 
 	// Phase 2: Restart apiserver with MultiCIDRServiceAllocator enabled
 	// This simulates the v1.32 -> v1.33 upgrade scenario
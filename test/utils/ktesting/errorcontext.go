@@ -68,11 +68,23 @@ func (tCtx TContext) finalize(err *error) {
 	if len(errs) == 0 {
 		return
 	}
-	*err = failures{errors.Join(errs...)}
+	*err = failures{errs: errs}
 }
 
+// failures wraps all errors recorded during a WithError scope. It implements
+// Unwrap() []error so that errors.Is and errors.As can find a specific error
+// among the ones that were joined together, the same way they would for an
+// error produced by errors.Join.
 type failures struct {
-	error
+	errs []error
+}
+
+func (e failures) Error() string {
+	return errors.Join(e.errs...).Error()
+}
+
+func (e failures) Unwrap() []error {
+	return e.errs
 }
 
 func (e failures) GomegaString() string {
@@ -121,6 +133,7 @@ func (tCtx TContext) Log(args ...any) {
 	tCtx.Helper()
 	// Enable `go vet printf` by directly calling fmt.Sprintln.
 	msg := strings.TrimSpace(fmt.Sprintln(args...))
+	tCtx.logStructuredStep("LOG", msg, 1)
 	tCtx.TB().Log(tCtx.buildHeader("", " ") + tCtx.steps + indent(msg, false))
 }
 
@@ -128,6 +141,7 @@ func (tCtx TContext) Logf(format string, args ...any) {
 	tCtx.Helper()
 	// Enable `go vet printf` by directly calling fmt.Sprintf.
 	msg := strings.TrimSpace(fmt.Sprintf(format, args...))
+	tCtx.logStructuredStep("LOG", msg, 1)
 	tCtx.TB().Log(tCtx.buildHeader("", " ") + tCtx.steps + indent(msg, false))
 }
 
@@ -135,6 +149,7 @@ func (tCtx TContext) Error(args ...any) {
 	if tCtx.capture == nil {
 		tCtx.Helper()
 		msg := strings.TrimSpace(fmt.Sprintln(args...))
+		tCtx.logStructuredStep("ERROR", msg, 1)
 		// ERROR *before* header to make it stand out as failure.
 		tCtx.TB().Error("ERROR:" + tCtx.buildHeader(" ", "\n") + indent(tCtx.steps+msg, true))
 		return
@@ -149,7 +164,7 @@ func (tCtx TContext) Error(args ...any) {
 	// line breaks. Besides, Sprintln (required for `go vet printf`) also
 	// adds a trailing newline that we don't want.
 	msg := strings.TrimSpace(fmt.Sprintln(args...))
-	tCtx.capture.errors = append(tCtx.capture.errors, errors.New(tCtx.steps+msg))
+	tCtx.capture.errors = append(tCtx.capture.errors, tCtx.captureError(args, msg))
 	tCtx.capture.failed = true
 }
 
@@ -158,6 +173,7 @@ func (tCtx TContext) Errorf(format string, args ...any) {
 		tCtx.Helper()
 		// Enable `go vet printf` by directly calling fmt.Sprintln.
 		msg := strings.TrimSpace(fmt.Sprintf(format, args...))
+		tCtx.logStructuredStep("ERROR", msg, 1)
 		// ERROR *before* header to make it stand out as failure.
 		tCtx.TB().Error("ERROR:" + tCtx.buildHeader(" ", "\n") + indent(tCtx.steps+msg, true))
 		return
@@ -167,10 +183,34 @@ func (tCtx TContext) Errorf(format string, args ...any) {
 	defer tCtx.capture.mutex.Unlock()
 
 	msg := strings.TrimSpace(fmt.Sprintf(format, args...))
-	tCtx.capture.errors = append(tCtx.capture.errors, errors.New(tCtx.steps+msg))
+	tCtx.capture.errors = append(tCtx.capture.errors, tCtx.captureError(args, msg))
 	tCtx.capture.failed = true
 }
 
+// captureError records msg as the error text, but if any of args is an error value,
+// that original error is wrapped instead of being collapsed into a new one. This
+// preserves it for errors.Is and errors.As across Error/Errorf/WithError.
+func (tCtx TContext) captureError(args []any, msg string) error {
+	if len(args) == 1 {
+		if err, ok := args[0].(error); ok {
+			return fmt.Errorf("%s%w", tCtx.steps, err)
+		}
+	}
+	verbs := make([]string, len(args))
+	hasErr := false
+	for i, arg := range args {
+		verbs[i] = "%v"
+		if _, ok := arg.(error); ok {
+			verbs[i] = "%w"
+			hasErr = true
+		}
+	}
+	if hasErr {
+		return fmt.Errorf("%s"+strings.Join(verbs, " "), append([]any{tCtx.steps}, args...)...)
+	}
+	return errors.New(tCtx.steps + msg)
+}
+
 func (tCtx TContext) Fail() {
 	if tCtx.capture == nil {
 		tCtx.TB().Fail()
@@ -207,11 +247,28 @@ func (tCtx TContext) Failed() bool {
 	return tCtx.capture.failed
 }
 
+// ErrorErr is a convenience wrapper around Error for the common case of forwarding
+// the error returned by some operation. The error is preserved as-is (see Error),
+// so callers can use errors.Is/errors.As against the final error of a WithError scope.
+func (tCtx TContext) ErrorErr(err error) {
+	tCtx.Helper()
+	tCtx.Error(err)
+}
+
+// FatalErr is a convenience wrapper around Fatal for the common case of forwarding
+// the error returned by some operation. The error is preserved as-is (see Error),
+// so callers can use errors.Is/errors.As against the final error of a WithError scope.
+func (tCtx TContext) FatalErr(err error) {
+	tCtx.Helper()
+	tCtx.Fatal(err)
+}
+
 func (tCtx TContext) Fatal(args ...any) {
 	if tCtx.capture == nil {
 		tCtx.Helper()
 		// Enable `go vet printf` by directly calling fmt.Sprintln.
 		msg := strings.TrimSpace(fmt.Sprintln(args...))
+		tCtx.logStructuredStep("FATAL", msg, 1)
 		// FATAL ERROR *before* header to make it stand out as failure.
 		tCtx.TB().Fatal("FATAL ERROR:" + tCtx.buildHeader(" ", "\n") + indent(tCtx.steps+msg, true))
 	}
@@ -225,6 +282,7 @@ func (tCtx TContext) Fatalf(format string, args ...any) {
 		tCtx.Helper()
 		// Enable `go vet printf` by directly calling fmt.Sprintf.
 		msg := strings.TrimSpace(fmt.Sprintf(format, args...))
+		tCtx.logStructuredStep("FATAL", msg, 1)
 		// FATAL ERROR *before* header to make it stand out as failure.
 		tCtx.TB().Fatal("FATAL ERROR:" + tCtx.buildHeader(" ", "\n") + indent(tCtx.steps+msg, true))
 		return
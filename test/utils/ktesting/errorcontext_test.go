@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ktesting
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+var errSentinel = errors.New("sentinel error")
+
+type typedError struct {
+	msg string
+}
+
+func (e *typedError) Error() string {
+	return e.msg
+}
+
+func TestWithErrorIs(t *testing.T) {
+	tCtx := Init(t)
+
+	doSomething := func(tCtx TContext) (finalErr error) {
+		tCtx, finalize := tCtx.WithError(&finalErr)
+		defer finalize()
+
+		tCtx.Error("wrapping the sentinel", errSentinel)
+		return finalErr
+	}
+
+	err := doSomething(tCtx)
+	if !errors.Is(err, errSentinel) {
+		t.Fatalf("expected errors.Is(err, errSentinel) to be true, got: %v", err)
+	}
+}
+
+func TestWithErrorAs(t *testing.T) {
+	tCtx := Init(t)
+
+	doSomething := func(tCtx TContext) (finalErr error) {
+		tCtx, finalize := tCtx.WithError(&finalErr)
+		defer finalize()
+
+		tCtx.Errorf("first failure")
+		tCtx.Error(&typedError{msg: "typed failure"})
+		tCtx.Errorf("last failure: %d", 42)
+		return finalErr
+	}
+
+	err := doSomething(tCtx)
+	var target *typedError
+	if !errors.As(err, &target) {
+		t.Fatalf("expected errors.As to find a *typedError in: %v", err)
+	}
+	if target.msg != "typed failure" {
+		t.Fatalf("expected typed error message %q, got %q", "typed failure", target.msg)
+	}
+}
+
+func TestErrorErrFatalErr(t *testing.T) {
+	tCtx := Init(t)
+
+	doSomething := func(tCtx TContext) (finalErr error) {
+		tCtx, finalize := tCtx.WithError(&finalErr)
+		defer finalize()
+
+		tCtx.FatalErr(fmt.Errorf("operation failed: %w", errSentinel))
+		return finalErr
+	}
+
+	err := doSomething(tCtx)
+	if !errors.Is(err, errSentinel) {
+		t.Fatalf("expected errors.Is(err, errSentinel) to be true, got: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ktesting
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// errConsistentlyFailed is used internally to stop polling in Consistently as soon as
+// the condition turns false; it never escapes this file.
+var errConsistentlyFailed = errors.New("condition became false")
+
+// Eventually polls cond every interval until it returns true, up to timeout. Each
+// attempt runs in its own WithError scope, so a failure recorded by cond on one attempt
+// (e.g. via tCtx.Errorf) does not propagate to the real test and is discarded once a
+// later attempt succeeds. If cond never returns true, the failure from its last attempt
+// is reported via tCtx.Fatal (including being captured instead of failing the test
+// immediately, if tCtx is itself inside a WithError scope). On success, Eventually
+// returns without recording any failure.
+//
+//	tCtx.Eventually(func(tCtx ktesting.TContext) bool {
+//	    return mgr.HasSynced()
+//	}, time.Minute, time.Second, "informer never synced")
+func (tCtx TContext) Eventually(cond func(tCtx TContext) bool, timeout, interval time.Duration, failureMessage string) {
+	tCtx.Helper()
+
+	var lastErr error
+	err := wait.PollUntilContextTimeout(tCtx, interval, timeout, true, func(ctx context.Context) (bool, error) {
+		var attemptErr error
+		var ok bool
+		func() {
+			childCtx, finalize := tCtx.WithError(&attemptErr)
+			defer finalize()
+			ok = cond(childCtx)
+		}()
+		lastErr = attemptErr
+		return ok, nil
+	})
+	if err != nil {
+		if lastErr != nil {
+			tCtx.Fatalf("%s: %v", failureMessage, lastErr)
+		}
+		tCtx.Fatalf("%s: %v", failureMessage, err)
+	}
+}
+
+// Consistently polls cond every interval for the entire duration and fails as soon as
+// cond returns false. Like Eventually, each attempt runs in its own WithError scope, so
+// the failure reported when cond turns false is whatever that attempt recorded, not a
+// generic message. If cond keeps returning true for the whole duration, Consistently
+// returns without recording any failure. Failures are reported via tCtx.Fatal and
+// therefore respect a surrounding WithError scope.
+//
+//	tCtx.Consistently(func(tCtx ktesting.TContext) bool {
+//	    return mgr.HasSynced()
+//	}, 10*time.Second, time.Second, "informer stopped being synced")
+func (tCtx TContext) Consistently(cond func(tCtx TContext) bool, duration, interval time.Duration, failureMessage string) {
+	tCtx.Helper()
+
+	var lastErr error
+	err := wait.PollUntilContextTimeout(tCtx, interval, duration, true, func(ctx context.Context) (bool, error) {
+		var attemptErr error
+		var ok bool
+		func() {
+			childCtx, finalize := tCtx.WithError(&attemptErr)
+			defer finalize()
+			ok = cond(childCtx)
+		}()
+		lastErr = attemptErr
+		if !ok {
+			return false, errConsistentlyFailed
+		}
+		return false, nil
+	})
+	switch {
+	case errors.Is(err, errConsistentlyFailed):
+		if lastErr != nil {
+			tCtx.Fatalf("%s: %v", failureMessage, lastErr)
+			return
+		}
+		tCtx.Fatalf("%s", failureMessage)
+	case errors.Is(err, context.DeadlineExceeded):
+		// The condition held for the entire duration: success.
+	case err != nil:
+		tCtx.Fatalf("%s: %v", failureMessage, err)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ktesting
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventuallySucceeds(t *testing.T) {
+	tCtx := Init(t)
+
+	count := 0
+	tCtx.Eventually(func(tCtx TContext) bool {
+		count++
+		return count >= 3
+	}, time.Second, time.Millisecond, "count never reached 3")
+
+	if count < 3 {
+		t.Fatalf("expected at least 3 polls, got %d", count)
+	}
+}
+
+func TestEventuallyTimesOut(t *testing.T) {
+	var finalErr error
+	tCtx, finalize := Init(t).WithError(&finalErr)
+	func() {
+		defer finalize()
+		tCtx.Eventually(func(tCtx TContext) bool {
+			return false
+		}, 20*time.Millisecond, 5*time.Millisecond, "condition never became true")
+	}()
+
+	if finalErr == nil {
+		t.Fatal("expected Eventually to record a failure when the condition never becomes true")
+	}
+}
+
+func TestConsistentlySucceeds(t *testing.T) {
+	tCtx := Init(t)
+
+	tCtx.Consistently(func(tCtx TContext) bool {
+		return true
+	}, 20*time.Millisecond, 5*time.Millisecond, "condition unexpectedly became false")
+}
+
+func TestConsistentlyFails(t *testing.T) {
+	var finalErr error
+	tCtx, finalize := Init(t).WithError(&finalErr)
+	func() {
+		defer finalize()
+		polls := 0
+		tCtx.Consistently(func(tCtx TContext) bool {
+			polls++
+			return polls < 2
+		}, time.Second, 5*time.Millisecond, "condition became false")
+	}()
+
+	if finalErr == nil {
+		t.Fatal("expected Consistently to record a failure once the condition turned false")
+	}
+}
+
+func TestEventuallyReportsLastAttemptError(t *testing.T) {
+	var finalErr error
+	tCtx, finalize := Init(t).WithError(&finalErr)
+	func() {
+		defer finalize()
+		count := 0
+		tCtx.Eventually(func(tCtx TContext) bool {
+			count++
+			tCtx.Errorf("attempt %d failed", count)
+			return false
+		}, 20*time.Millisecond, 5*time.Millisecond, "condition never became true")
+	}()
+
+	if finalErr == nil {
+		t.Fatal("expected Eventually to record a failure when the condition never becomes true")
+	}
+	if count := strings.Count(finalErr.Error(), "attempt"); count != 1 {
+		t.Fatalf("expected only the last attempt's error to be reported, got %d occurrences in %q", count, finalErr.Error())
+	}
+}
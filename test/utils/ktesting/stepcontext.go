@@ -16,6 +16,8 @@ limitations under the License.
 
 package ktesting
 
+import "context"
+
 // WithStep creates a context where a prefix is added to all errors and log
 // messages, similar to how errors are wrapped. This can be nested, leaving a
 // trail of "bread crumbs" that help figure out where in a test some problem
@@ -26,9 +28,30 @@ package ktesting
 // The string should describe the operation that is about to happen ("starting
 // the controller", "list items") or what is being operated on ("HTTP server").
 // Multiple different prefixes get concatenated with a colon.
+//
+// In addition to the human-readable, concatenated form, each step is also
+// recorded individually and can be retrieved in order with [StepTrail] for
+// callers that want to consume the trail programmatically instead of parsing
+// the rendered string, or as a full [StepFrame] (name, start time, attributes)
+// with [StepFrames]. Use [TContext.WithStepAttrs] instead of WithStep to attach
+// key/value attributes to the step's StepFrame.
 func (tCtx TContext) WithStep(step string) TContext {
-	tCtx.steps += step + ": "
-	return tCtx
+	return tCtx.withStepFrame(step, nil)
+}
+
+// stepTrailKey is the context key under which the structured step trail is stored.
+type stepTrailKey struct{}
+
+// StepTrail returns the sequence of step descriptions added via [TContext.WithStep] or
+// [TContext.Step], in the order they were nested, outermost first. It returns nil if no
+// step has been added. The returned slice is a copy and safe for the caller to keep or
+// modify.
+func StepTrail(ctx context.Context) []string {
+	trail, _ := ctx.Value(stepTrailKey{}).([]string)
+	if trail == nil {
+		return nil
+	}
+	return append([]string(nil), trail...)
 }
 
 // Step is useful when the context with the step information is
@@ -0,0 +1,77 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ktesting
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStepTrail(t *testing.T) {
+	tCtx := Init(t)
+
+	if trail := StepTrail(tCtx); trail != nil {
+		t.Fatalf("expected no step trail initially, got: %v", trail)
+	}
+
+	tCtx = tCtx.WithStep("bake cake").WithStep("set heat for baking")
+
+	expected := []string{"bake cake", "set heat for baking"}
+	if trail := StepTrail(tCtx); !reflect.DeepEqual(trail, expected) {
+		t.Fatalf("expected step trail %v, got %v", expected, trail)
+	}
+}
+
+func TestStepTrailIsIndependentPerBranch(t *testing.T) {
+	tCtx := Init(t).WithStep("shared")
+
+	branchA := tCtx.WithStep("branch A")
+	branchB := tCtx.WithStep("branch B")
+
+	if trail := StepTrail(branchA); !reflect.DeepEqual(trail, []string{"shared", "branch A"}) {
+		t.Fatalf("unexpected trail for branch A: %v", trail)
+	}
+	if trail := StepTrail(branchB); !reflect.DeepEqual(trail, []string{"shared", "branch B"}) {
+		t.Fatalf("unexpected trail for branch B: %v", trail)
+	}
+}
+
+func TestStepFrames(t *testing.T) {
+	tCtx := Init(t)
+
+	if frames := StepFrames(tCtx); frames != nil {
+		t.Fatalf("expected no step frames initially, got: %v", frames)
+	}
+
+	tCtx = tCtx.WithStep("bake cake").WithStepAttrs("set heat", "degrees", 180)
+
+	frames := StepFrames(tCtx)
+	if len(frames) != 2 {
+		t.Fatalf("expected 2 step frames, got %d: %v", len(frames), frames)
+	}
+	if frames[0].Name != "bake cake" || frames[0].Attrs != nil {
+		t.Fatalf("unexpected first frame: %+v", frames[0])
+	}
+	if frames[1].Name != "set heat" || frames[1].Attrs["degrees"] != 180 {
+		t.Fatalf("unexpected second frame: %+v", frames[1])
+	}
+	for _, frame := range frames {
+		if frame.StartTime.IsZero() {
+			t.Fatalf("expected a non-zero start time on frame %q", frame.Name)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ktesting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	goruntime "runtime"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// StepFrame is one entry in the structured step trail recorded via [TContext.WithStep] or
+// [TContext.WithStepAttrs]: the step's name, when it started, and any caller-supplied
+// key/value attributes. It is the machine-readable counterpart of the colon-delimited
+// string that WithStep also builds up, for callers (e.g. CI post-processors) that want to
+// group failures by step path instead of substring-matching that string.
+type StepFrame struct {
+	Name      string
+	StartTime time.Time
+	Attrs     map[string]any
+}
+
+// stepFramesKey is the context key under which the []StepFrame trail is stored.
+type stepFramesKey struct{}
+
+// StepFrames returns the structured step trail added via [TContext.WithStep] or
+// [TContext.WithStepAttrs], in the order the steps were nested, outermost first. It
+// returns nil if no step has been added. The returned slice is a copy and safe for the
+// caller to keep or modify.
+func StepFrames(ctx context.Context) []StepFrame {
+	frames, _ := ctx.Value(stepFramesKey{}).([]StepFrame)
+	if frames == nil {
+		return nil
+	}
+	return append([]StepFrame(nil), frames...)
+}
+
+// WithStepAttrs behaves like [TContext.WithStep], but additionally attaches kv
+// (alternating key/value pairs, the same convention as logr.Logger.WithValues) to the
+// step's [StepFrame] so it shows up in the structured log records written by Log,
+// Error/Errorf, and Fatal/Fatalf.
+func (tCtx TContext) WithStepAttrs(step string, kv ...any) TContext {
+	return tCtx.withStepFrame(step, attrsFromKV(kv))
+}
+
+// withStepFrame is the shared implementation behind WithStep and WithStepAttrs: it
+// extends both the human-readable "a: b: c" prefix and the structured []StepFrame trail.
+func (tCtx TContext) withStepFrame(step string, attrs map[string]any) TContext {
+	tCtx.steps += step + ": "
+	tCtx.Context = context.WithValue(tCtx.Context, stepTrailKey{}, append(StepTrail(tCtx), step))
+	frame := StepFrame{Name: step, StartTime: time.Now(), Attrs: attrs}
+	tCtx.Context = context.WithValue(tCtx.Context, stepFramesKey{}, append(StepFrames(tCtx), frame))
+	return tCtx
+}
+
+func attrsFromKV(kv []any) map[string]any {
+	if len(kv) == 0 {
+		return nil
+	}
+	attrs := make(map[string]any, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i += 2 {
+		key := fmt.Sprintf("%v", kv[i])
+		if i+1 < len(kv) {
+			attrs[key] = kv[i+1]
+		} else {
+			attrs[key] = nil
+		}
+	}
+	return attrs
+}
+
+// structuredStepLogEnvVar opts a process into the JSON step-trail emitter documented on
+// logStructuredStep. It is unset by default so the existing string-concatenation
+// behavior (tCtx.steps, stepReporter) remains what Ginkgo progress reports see.
+const structuredStepLogEnvVar = "KTESTING_STRUCTURED_STEP_LOG"
+
+// jsonStepFrame is the on-the-wire shape of a StepFrame in the JSON emitter, with
+// StartTime and an ElapsedSeconds convenience field for post-processors that don't want
+// to parse timestamps themselves.
+type jsonStepFrame struct {
+	Name           string         `json:"name"`
+	StartTime      time.Time      `json:"startTime"`
+	ElapsedSeconds float64        `json:"elapsedSeconds"`
+	Attrs          map[string]any `json:"attrs,omitempty"`
+}
+
+// jsonStepLogRecord is one line written by the JSON emitter: a single Log/Error/Fatal
+// call, the full step stack active at that point, and the file:line it was called from.
+type jsonStepLogRecord struct {
+	Level   string          `json:"level"`
+	Message string          `json:"message"`
+	File    string          `json:"file"`
+	Line    int             `json:"line"`
+	Steps   []jsonStepFrame `json:"steps,omitempty"`
+}
+
+// logStructuredStep records msg against the active step trail via the contextual klog
+// logger stored in tCtx.Context (as a "test.step" value, following
+// logr.Logger.WithValues), and, when KTESTING_STRUCTURED_STEP_LOG is set in the
+// environment, additionally writes one JSON object per call to stderr containing the
+// full step stack, elapsed time per frame, and the caller's file:line. skip is the number
+// of additional stack frames to skip past logStructuredStep itself to reach the original
+// caller (e.g. Log, Errorf) for the file:line recorded in the JSON record.
+func (tCtx TContext) logStructuredStep(level, msg string, skip int) {
+	frames := StepFrames(tCtx)
+
+	logger := klog.FromContext(tCtx)
+	if len(frames) > 0 {
+		names := make([]string, len(frames))
+		for i, frame := range frames {
+			names[i] = frame.Name
+		}
+		logger = logger.WithValues("test.step", names)
+	}
+	logger.V(4).Info(msg, "level", level)
+
+	if os.Getenv(structuredStepLogEnvVar) == "" {
+		return
+	}
+
+	now := time.Now()
+	jsonFrames := make([]jsonStepFrame, len(frames))
+	for i, frame := range frames {
+		jsonFrames[i] = jsonStepFrame{
+			Name:           frame.Name,
+			StartTime:      frame.StartTime,
+			ElapsedSeconds: now.Sub(frame.StartTime).Seconds(),
+			Attrs:          frame.Attrs,
+		}
+	}
+
+	_, file, line, _ := goruntime.Caller(skip + 1)
+	record := jsonStepLogRecord{
+		Level:   level,
+		Message: msg,
+		File:    file,
+		Line:    line,
+		Steps:   jsonFrames,
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(os.Stderr, string(data))
+}
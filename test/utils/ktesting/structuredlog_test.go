@@ -0,0 +1,107 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ktesting
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureStderrJSON redirects os.Stderr for the duration of fn and returns every line
+// written to it, decoded as a jsonStepLogRecord.
+func captureStderrJSON(t *testing.T, fn func()) []jsonStepLogRecord {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	original := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = original }()
+
+	fn()
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close pipe writer: %v", err)
+	}
+
+	var records []jsonStepLogRecord
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var record jsonStepLogRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("failed to decode JSON step log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("failed to read captured stderr: %v", err)
+	}
+	return records
+}
+
+func TestStructuredStepLogDisabledByDefault(t *testing.T) {
+	tCtx := Init(t).WithStep("bake cake")
+
+	records := captureStderrJSON(t, func() {
+		tCtx.Log("preheating oven")
+	})
+	if len(records) != 0 {
+		t.Fatalf("expected no JSON step log records without %s set, got: %+v", structuredStepLogEnvVar, records)
+	}
+}
+
+func TestStructuredStepLogEmitsStepStack(t *testing.T) {
+	t.Setenv(structuredStepLogEnvVar, "1")
+
+	tCtx := Init(t).WithStep("bake cake").WithStepAttrs("set heat", "degrees", 180)
+
+	records := captureStderrJSON(t, func() {
+		tCtx.Log("preheating oven")
+	})
+	if len(records) != 1 {
+		t.Fatalf("expected exactly one JSON step log record, got %d: %+v", len(records), records)
+	}
+
+	record := records[0]
+	if record.Level != "LOG" {
+		t.Fatalf("expected level LOG, got %q", record.Level)
+	}
+	if record.Message != "preheating oven" {
+		t.Fatalf("expected message %q, got %q", "preheating oven", record.Message)
+	}
+	if !strings.HasSuffix(record.File, "structuredlog_test.go") {
+		t.Fatalf("expected file:line to point at the caller, got %q:%d", record.File, record.Line)
+	}
+	if len(record.Steps) != 2 {
+		t.Fatalf("expected 2 step frames, got %d: %+v", len(record.Steps), record.Steps)
+	}
+	if record.Steps[0].Name != "bake cake" {
+		t.Fatalf("unexpected first step frame: %+v", record.Steps[0])
+	}
+	if record.Steps[1].Name != "set heat" || record.Steps[1].Attrs["degrees"] != float64(180) {
+		t.Fatalf("unexpected second step frame: %+v", record.Steps[1])
+	}
+}